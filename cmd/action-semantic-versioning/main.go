@@ -1,15 +1,19 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/netwarlan/action-semantic-versioning/internal/action"
-	"github.com/netwarlan/action-semantic-versioning/internal/changelog"
-	"github.com/netwarlan/action-semantic-versioning/internal/commit"
 	"github.com/netwarlan/action-semantic-versioning/internal/git"
 	"github.com/netwarlan/action-semantic-versioning/internal/github"
-	"github.com/netwarlan/action-semantic-versioning/internal/semver"
+	"github.com/netwarlan/action-semantic-versioning/internal/runner"
 )
 
 func main() {
@@ -25,145 +29,94 @@ func run() error {
 		return err
 	}
 
-	// Validate default version is valid semver.
-	if _, err := semver.Parse(inputs.DefaultVersion); err != nil {
-		return fmt.Errorf("invalid default-version %q: %w", inputs.DefaultVersion, err)
+	timeout, err := time.ParseDuration(inputs.APITimeout)
+	if err != nil || timeout <= 0 {
+		return fmt.Errorf("invalid api-timeout %q: must be a duration such as 30s", inputs.APITimeout)
 	}
-
-	gitClient := &git.Client{}
-
-	// Check for shallow clone.
-	shallow, err := gitClient.IsShallowRepository()
-	if err != nil {
-		return fmt.Errorf("checking repository depth: %w", err)
-	}
-	if shallow {
-		return fmt.Errorf("shallow clone detected — use 'actions/checkout' with 'fetch-depth: 0' to fetch full history")
+	retries, err := strconv.Atoi(inputs.APIRetries)
+	if err != nil || retries < 0 {
+		return fmt.Errorf("invalid api-retries %q: must be a number of retries", inputs.APIRetries)
 	}
 
-	// Find latest semver tag.
-	latestTag, err := gitClient.FindLatestSemverTag(inputs.TagPrefix)
-	if err != nil {
-		return fmt.Errorf("finding latest tag: %w", err)
-	}
+	// Cancelling the workflow interrupts the action; stop waiting on the API.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	isInitial := latestTag == ""
-	previousVersion := latestTag
-	if isInitial {
-		previousVersion = ""
-		fmt.Printf("No existing semver tags found. Will use default version: %s\n", inputs.DefaultVersion)
-	} else {
-		fmt.Printf("Latest version tag: %s\n", latestTag)
-	}
+	api := github.NewClient(timeout)
+	api.MaxAttempts = retries + 1
 
-	// List commits since last tag.
-	rawCommits, err := gitClient.ListCommitsSince(latestTag)
-	if err != nil {
-		return fmt.Errorf("listing commits: %w", err)
-	}
-
-	if len(rawCommits) == 0 {
-		fmt.Println("No new commits since last tag.")
-		return writeSkippedOutputs(previousVersion)
+	if inputs.AppID != "" {
+		token, err := appToken(ctx, api, inputs)
+		if err != nil {
+			return err
+		}
+		inputs.Token = token
 	}
 
-	fmt.Printf("Found %d commit(s) since last tag.\n", len(rawCommits))
-
-	// Parse commits.
-	var commits []commit.ConventionalCommit
-	for _, rc := range rawCommits {
-		commits = append(commits, commit.Parse(rc.Hash, rc.Message))
+	gitClient, err := git.New(inputs.GitBackend, "", inputs.Token)
+	if err != nil {
+		return fmt.Errorf("invalid git-backend: %w", err)
 	}
 
-	// Determine bump type.
-	bumpType := commit.DetermineBump(commits, inputs.BumpPatchOnUnknown)
+	releases := github.NewReleaseClient(inputs.Token)
+	releases.HTTP = api
+	releases.Context = ctx
 
-	if bumpType == commit.BumpNone {
-		fmt.Println("No version-bumping commits found.")
-		return writeSkippedOutputs(previousVersion)
+	r := &runner.Runner{
+		Inputs:     inputs,
+		Git:        gitClient,
+		Releases:   releases,
+		Issues:     releases,
+		Milestones: releases,
 	}
-
-	// Calculate new version.
-	var newVersion semver.Version
-	if isInitial {
-		// Use the default version directly for the initial release.
-		newVersion, _ = semver.Parse(inputs.DefaultVersion)
-	} else {
-		current, _ := semver.Parse(latestTag)
-		switch bumpType {
-		case commit.BumpMajor:
-			newVersion = current.BumpMajor()
-		case commit.BumpMinor:
-			newVersion = current.BumpMinor()
-		case commit.BumpPatch:
-			newVersion = current.BumpPatch()
+	if inputs.PRPreview {
+		pr, _, err := action.PullRequestEvent()
+		if err != nil {
+			return err
 		}
+		r.PullRequest = pr
 	}
-
-	newTag := newVersion.String()
-	changelogText := changelog.Generate(commits, previousVersion, newTag)
-
-	fmt.Printf("Bump type: %s\n", bumpType)
-	fmt.Printf("New version: %s\n", newTag)
-
-	if !inputs.DryRun {
-		// Create and push tag.
-		fmt.Printf("Creating tag %s...\n", newTag)
-		if err := gitClient.CreateTag(newTag); err != nil {
-			return fmt.Errorf("creating tag: %w", err)
-		}
-
-		fmt.Printf("Pushing tag %s...\n", newTag)
-		if err := gitClient.PushTag(newTag); err != nil {
-			return fmt.Errorf("pushing tag: %w", err)
-		}
-
-		// Create release if requested.
-		if inputs.CreateRelease {
-			fmt.Println("Creating GitHub release...")
-			releaseClient := github.NewReleaseClient(inputs.Token)
-			if err := releaseClient.CreateRelease(
-				newTag,
-				newTag,
-				changelogText,
-				inputs.ReleaseDraft,
-				inputs.ReleasePrerelease,
-			); err != nil {
-				return fmt.Errorf("creating release: %w", err)
-			}
-			fmt.Println("Release created successfully.")
-		}
-	} else {
-		fmt.Println("Dry run — no tag or release created.")
+	result, err := r.Run()
+	if err != nil {
+		return err
 	}
 
 	// Write outputs.
-	for _, o := range []struct{ k, v string }{
-		{"previous-version", previousVersion},
-		{"new-version", newTag},
-		{"bump-type", bumpType.String()},
-		{"changelog", changelogText},
-		{"skipped", "false"},
-	} {
-		if err := action.SetOutput(o.k, o.v); err != nil {
-			return fmt.Errorf("setting output %s: %w", o.k, err)
+	for _, o := range result.Outputs() {
+		if err := action.SetOutput(o.Name, o.Value); err != nil {
+			return fmt.Errorf("setting output %s: %w", o.Name, err)
 		}
 	}
 
 	return nil
 }
 
-func writeSkippedOutputs(previousVersion string) error {
-	for _, o := range []struct{ k, v string }{
-		{"previous-version", previousVersion},
-		{"new-version", ""},
-		{"bump-type", "none"},
-		{"changelog", ""},
-		{"skipped", "true"},
-	} {
-		if err := action.SetOutput(o.k, o.v); err != nil {
-			return fmt.Errorf("setting output %s: %w", o.k, err)
-		}
+// appToken authenticates as the GitHub App in inputs and returns an
+// installation token. The git CLI is configured to push with it; go-git
+// authenticates with the token directly and may run without a git binary.
+func appToken(ctx context.Context, api *github.Client, inputs action.Inputs) (string, error) {
+	app, err := github.NewAppClient(inputs.AppID, inputs.AppPrivateKey)
+	if err != nil {
+		return "", fmt.Errorf("invalid app-private-key: %w", err)
 	}
-	return nil
+	app.HTTP = api
+	app.Context = ctx
+
+	token, err := app.InstallationToken()
+	if err != nil {
+		return "", fmt.Errorf("authenticating as GitHub App %s: %w", inputs.AppID, err)
+	}
+	action.Mask(token)
+
+	if strings.EqualFold(inputs.GitBackend, "go-git") {
+		return token, nil
+	}
+	serverURL := os.Getenv("GITHUB_SERVER_URL")
+	if serverURL == "" {
+		serverURL = "https://github.com"
+	}
+	if err := git.ConfigureToken("", serverURL, token); err != nil {
+		return "", fmt.Errorf("configuring git credentials: %w", err)
+	}
+	return token, nil
 }
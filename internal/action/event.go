@@ -0,0 +1,50 @@
+package action
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// PullRequest identifies the pull request a workflow runs for.
+type PullRequest struct {
+	Number  int
+	BaseRef string // branch the pull request merges into
+	HeadSHA string // last commit of the pull request branch
+}
+
+// PullRequestEvent reads the pull request from the webhook payload at
+// GITHUB_EVENT_PATH. ok is false for events without a pull request.
+func PullRequestEvent() (pr PullRequest, ok bool, err error) {
+	path := os.Getenv("GITHUB_EVENT_PATH")
+	if path == "" {
+		return PullRequest{}, false, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return PullRequest{}, false, fmt.Errorf("read event payload: %w", err)
+	}
+
+	var event struct {
+		PullRequest *struct {
+			Number int `json:"number"`
+			Base   struct {
+				Ref string `json:"ref"`
+			} `json:"base"`
+			Head struct {
+				SHA string `json:"sha"`
+			} `json:"head"`
+		} `json:"pull_request"`
+	}
+	if err := json.Unmarshal(data, &event); err != nil {
+		return PullRequest{}, false, fmt.Errorf("parse event payload: %w", err)
+	}
+	if event.PullRequest == nil {
+		return PullRequest{}, false, nil
+	}
+	return PullRequest{
+		Number:  event.PullRequest.Number,
+		BaseRef: event.PullRequest.Base.Ref,
+		HeadSHA: event.PullRequest.Head.SHA,
+	}, true, nil
+}
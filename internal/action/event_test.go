@@ -0,0 +1,45 @@
+package action
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeEvent(t *testing.T, payload string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "event.json")
+	if err := os.WriteFile(path, []byte(payload), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GITHUB_EVENT_PATH", path)
+}
+
+func TestPullRequestEvent(t *testing.T) {
+	writeEvent(t, `{"action": "synchronize", "number": 12, "pull_request": {"number": 12, "base": {"ref": "main", "sha": "aaa"}, "head": {"ref": "feat/x", "sha": "bbb"}}}`)
+
+	pr, ok, err := PullRequestEvent()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok || pr != (PullRequest{Number: 12, BaseRef: "main", HeadSHA: "bbb"}) {
+		t.Errorf("PullRequestEvent() = %+v, %v", pr, ok)
+	}
+}
+
+func TestPullRequestEventOther(t *testing.T) {
+	writeEvent(t, `{"ref": "refs/heads/main", "after": "bbb"}`)
+	if _, ok, err := PullRequestEvent(); ok || err != nil {
+		t.Errorf("push event: ok = %v, err = %v", ok, err)
+	}
+
+	writeEvent(t, `{`)
+	if _, _, err := PullRequestEvent(); err == nil {
+		t.Error("expected error for invalid payload")
+	}
+
+	t.Setenv("GITHUB_EVENT_PATH", "")
+	if _, ok, err := PullRequestEvent(); ok || err != nil {
+		t.Errorf("no payload: ok = %v, err = %v", ok, err)
+	}
+}
@@ -8,32 +8,113 @@ import (
 
 // Inputs holds the parsed GitHub Action inputs.
 type Inputs struct {
-	Token              string
-	DefaultVersion     string
-	TagPrefix          string
-	CreateRelease      bool
-	ReleaseDraft       bool
-	ReleasePrerelease  bool
-	BumpPatchOnUnknown bool
-	DryRun             bool
+	Token                    string
+	DefaultVersion           string
+	TagPrefix                string
+	TagSuffix                string
+	TagPatterns              string
+	CreateRelease            bool
+	ReleaseDraft             bool
+	ReleasePrerelease        bool
+	BumpPatchOnUnknown       bool
+	DryRun                   bool
+	CommitConvention         string
+	CommitPattern            string
+	GitBackend               string
+	IncludeUnreachableTags   bool
+	Branch                   string
+	MaintenanceFeaturePolicy string
+	Branches                 string
+	Snapshot                 bool
+	SnapshotIdentifier       string
+	SnapshotTimestamp        bool
+	VersionScheme            string
+	CalverFormat             string
+	VersionFiles             string
+	ReleaseCommitMessage     string
+	VersionSource            string
+	VersionFile              string
+	ChangelogFile            string
+	CommitUserName           string
+	CommitUserEmail          string
+	Assets                   string
+	AssetChecksums           bool
+	ExistingRelease          string
+	APITimeout               string
+	APIRetries               string
+	AppID                    string
+	AppPrivateKey            string
+	ChangelogSource          string
+	ReleaseComments          bool
+	ReleaseLabel             string
+	PRPreview                bool
+	Milestones               bool
 }
 
 // ParseInputs reads action inputs from INPUT_* environment variables.
 func ParseInputs() (Inputs, error) {
+	// A GitHub App's installation token replaces the token input.
 	token := getInput("TOKEN")
-	if token == "" {
+	appID, appKey := getInput("APP-ID"), getInput("APP-PRIVATE-KEY")
+	switch {
+	case appID != "" && appKey == "":
+		return Inputs{}, fmt.Errorf("input 'app-private-key' is required with 'app-id'")
+	case appID == "" && appKey != "":
+		return Inputs{}, fmt.Errorf("input 'app-id' is required with 'app-private-key'")
+	case token == "" && appID == "":
 		return Inputs{}, fmt.Errorf("input 'token' is required")
 	}
 
+	// A pull request preview releases from the branch the pull request
+	// merges into, not from its merge ref.
+	prPreview := parseBool(getInput("PR-PREVIEW"))
+	branch := os.Getenv("GITHUB_REF_NAME")
+	if base := os.Getenv("GITHUB_BASE_REF"); prPreview && base != "" {
+		branch = base
+	}
+
 	return Inputs{
-		Token:              token,
-		DefaultVersion:     getInputDefault("DEFAULT-VERSION", "v0.1.0"),
-		TagPrefix:          getInputDefault("TAG-PREFIX", "v"),
-		CreateRelease:      parseBool(getInput("CREATE-RELEASE")),
-		ReleaseDraft:       parseBool(getInput("RELEASE-DRAFT")),
-		ReleasePrerelease:  parseBool(getInput("RELEASE-PRERELEASE")),
-		BumpPatchOnUnknown: parseBool(getInput("BUMP-PATCH-ON-UNKNOWN")),
-		DryRun:             parseBool(getInput("DRY-RUN")),
+		Token:                    token,
+		DefaultVersion:           getInputDefault("DEFAULT-VERSION", "v0.1.0"),
+		TagPrefix:                getInput("TAG-PREFIX"),
+		TagSuffix:                getInput("TAG-SUFFIX"),
+		TagPatterns:              getInput("TAG-PATTERNS"),
+		CreateRelease:            parseBool(getInput("CREATE-RELEASE")),
+		ReleaseDraft:             parseBool(getInput("RELEASE-DRAFT")),
+		ReleasePrerelease:        parseBool(getInput("RELEASE-PRERELEASE")),
+		BumpPatchOnUnknown:       parseBool(getInput("BUMP-PATCH-ON-UNKNOWN")),
+		DryRun:                   parseBool(getInput("DRY-RUN")),
+		CommitConvention:         getInputDefault("COMMIT-CONVENTION", "conventional"),
+		CommitPattern:            getInput("COMMIT-PATTERN"),
+		GitBackend:               getInputDefault("GIT-BACKEND", "cli"),
+		IncludeUnreachableTags:   parseBool(getInput("INCLUDE-UNREACHABLE-TAGS")),
+		Branch:                   getInputDefault("BRANCH", branch),
+		MaintenanceFeaturePolicy: getInputDefault("MAINTENANCE-FEATURE-POLICY", "fail"),
+		Branches:                 getInput("BRANCHES"),
+		Snapshot:                 parseBool(getInput("SNAPSHOT")),
+		SnapshotIdentifier:       getInputDefault("SNAPSHOT-IDENTIFIER", "dev"),
+		SnapshotTimestamp:        parseBool(getInput("SNAPSHOT-TIMESTAMP")),
+		VersionScheme:            getInputDefault("VERSION-SCHEME", "semver"),
+		CalverFormat:             getInputDefault("CALVER-FORMAT", "YYYY.MM.MICRO"),
+		VersionFiles:             getInput("VERSION-FILES"),
+		ReleaseCommitMessage:     getInputDefault("RELEASE-COMMIT-MESSAGE", "chore(release): {version}"),
+		VersionSource:            getInputDefault("VERSION-SOURCE", "tag"),
+		VersionFile:              getInputDefault("VERSION-FILE", "VERSION"),
+		ChangelogFile:            getInput("CHANGELOG-FILE"),
+		CommitUserName:           getInputDefault("COMMIT-USER-NAME", "github-actions[bot]"),
+		CommitUserEmail:          getInputDefault("COMMIT-USER-EMAIL", "41898282+github-actions[bot]@users.noreply.github.com"),
+		Assets:                   getInput("ASSETS"),
+		AssetChecksums:           parseBool(getInput("ASSET-CHECKSUMS")),
+		ExistingRelease:          getInputDefault("EXISTING-RELEASE", "fail"),
+		APITimeout:               getInputDefault("API-TIMEOUT", "30s"),
+		APIRetries:               getInputDefault("API-RETRIES", "3"),
+		AppID:                    appID,
+		AppPrivateKey:            appKey,
+		ChangelogSource:          getInputDefault("CHANGELOG-SOURCE", "commits"),
+		ReleaseComments:          parseBool(getInput("RELEASE-COMMENTS")),
+		ReleaseLabel:             getInput("RELEASE-LABEL"),
+		PRPreview:                prPreview,
+		Milestones:               parseBool(getInput("MILESTONES")),
 	}, nil
 }
 
@@ -33,6 +33,11 @@ func SetOutput(name, value string) error {
 	return err
 }
 
+// Mask hides value, such as a generated token, in the workflow log.
+func Mask(value string) {
+	fmt.Printf("::add-mask::%s\n", value)
+}
+
 func randomDelimiter() string {
 	b := make([]byte, 16)
 	_, _ = rand.Read(b)
@@ -0,0 +1,130 @@
+// Package assets collects the files attached to a release.
+package assets
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ChecksumsName is the name of the generated checksums asset.
+const ChecksumsName = "SHA256SUMS"
+
+// Asset is a file to upload.
+type Asset struct {
+	Name        string // file name on the release
+	Path        string // path on disk
+	ContentType string
+}
+
+// Collect expands glob patterns, one per line or separated by commas,
+// relative to dir. Every pattern must match at least one file, and files
+// must have distinct base names since that is their name on the release.
+func Collect(patterns, dir string) ([]Asset, error) {
+	var assets []Asset
+	seen := map[string]string{}
+	for _, p := range strings.FieldsFunc(patterns, func(c rune) bool { return c == ',' || c == '\n' }) {
+		if p = strings.TrimSpace(p); p == "" {
+			continue
+		}
+		matches, err := filepath.Glob(filepath.Join(dir, p))
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", p, err)
+		}
+		found := false
+		for _, path := range matches {
+			if info, err := os.Stat(path); err != nil || info.IsDir() {
+				continue
+			}
+			found = true
+			name := filepath.Base(path)
+			if prev, ok := seen[name]; ok {
+				if prev == path {
+					continue
+				}
+				return nil, fmt.Errorf("%s and %s would both be uploaded as %s", prev, path, name)
+			}
+			seen[name] = path
+			assets = append(assets, Asset{Name: name, Path: path, ContentType: ContentType(name)})
+		}
+		if !found {
+			return nil, fmt.Errorf("no files match %q", p)
+		}
+	}
+	sort.Slice(assets, func(i, j int) bool { return assets[i].Name < assets[j].Name })
+	return assets, nil
+}
+
+// contentTypes covers release file types that mime.TypeByExtension does not
+// know or reports inconsistently across systems.
+var contentTypes = map[string]string{
+	".tar.gz":  "application/gzip",
+	".tgz":     "application/gzip",
+	".gz":      "application/gzip",
+	".tar":     "application/x-tar",
+	".tar.xz":  "application/x-xz",
+	".xz":      "application/x-xz",
+	".tar.bz2": "application/x-bzip2",
+	".bz2":     "application/x-bzip2",
+	".tar.zst": "application/zstd",
+	".zst":     "application/zstd",
+	".zip":     "application/zip",
+	".7z":      "application/x-7z-compressed",
+	".deb":     "application/vnd.debian.binary-package",
+	".rpm":     "application/x-rpm",
+	".apk":     "application/vnd.android.package-archive",
+	".dmg":     "application/x-apple-diskimage",
+	".msi":     "application/x-msi",
+	".exe":     "application/vnd.microsoft.portable-executable",
+	".jar":     "application/java-archive",
+	".whl":     "application/zip",
+	".json":    "application/json",
+	".txt":     "text/plain",
+	".md":      "text/markdown",
+	".sig":     "application/pgp-signature",
+	".asc":     "application/pgp-signature",
+	".pem":     "application/x-pem-file",
+	".sbom":    "application/json",
+}
+
+// ContentType returns the MIME type for a file name, defaulting to
+// application/octet-stream.
+func ContentType(name string) string {
+	lower := strings.ToLower(name)
+	if lower == strings.ToLower(ChecksumsName) {
+		return "text/plain"
+	}
+	for _, ext := range []string{".tar.gz", ".tar.xz", ".tar.bz2", ".tar.zst"} {
+		if strings.HasSuffix(lower, ext) {
+			return contentTypes[ext]
+		}
+	}
+	ext := filepath.Ext(lower)
+	if t, ok := contentTypes[ext]; ok {
+		return t
+	}
+	if t := mime.TypeByExtension(ext); t != "" {
+		return t
+	}
+	return "application/octet-stream"
+}
+
+// Checksums returns a SHA256SUMS file for assets, in the format read by
+// sha256sum --check.
+func Checksums(assets []Asset) ([]byte, error) {
+	var sb strings.Builder
+	for _, a := range assets {
+		data, err := os.ReadFile(a.Path)
+		if err != nil {
+			return nil, err
+		}
+		sum := sha256.Sum256(data)
+		fmt.Fprintf(&sb, "%s  %s\n", hex.EncodeToString(sum[:]), a.Name)
+	}
+	return []byte(sb.String()), nil
+}
@@ -0,0 +1,109 @@
+package assets
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestCollect(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"dist/app-linux.tar.gz": "linux",
+		"dist/app-windows.zip":  "windows",
+		"dist/notes.txt":        "notes",
+		"sbom.json":             "{}",
+	})
+
+	assets, err := Collect("dist/*.tar.gz, dist/*.zip\nsbom.json\ndist/app-*", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Asset{
+		{Name: "app-linux.tar.gz", Path: filepath.Join(dir, "dist/app-linux.tar.gz"), ContentType: "application/gzip"},
+		{Name: "app-windows.zip", Path: filepath.Join(dir, "dist/app-windows.zip"), ContentType: "application/zip"},
+		{Name: "sbom.json", Path: filepath.Join(dir, "sbom.json"), ContentType: "application/json"},
+	}
+	if len(assets) != len(want) {
+		t.Fatalf("Collect() = %+v", assets)
+	}
+	for i := range want {
+		if assets[i] != want[i] {
+			t.Errorf("assets[%d] = %+v, want %+v", i, assets[i], want[i])
+		}
+	}
+}
+
+func TestCollectErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"linux/app":   "linux",
+		"darwin/app":  "darwin",
+		"dist/a.zip":  "a",
+		"empty/.keep": "",
+	})
+
+	tests := []struct {
+		patterns string
+		want     string
+	}{
+		{"dist/*.tar.gz", "no files match"},
+		{"*/app", "both be uploaded as app"},
+		{"dist/[", "invalid pattern"},
+		{"empty", "no files match"},
+	}
+	for _, tt := range tests {
+		if _, err := Collect(tt.patterns, dir); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Collect(%q) err = %v, want %q", tt.patterns, err, tt.want)
+		}
+	}
+}
+
+func TestContentType(t *testing.T) {
+	tests := map[string]string{
+		"app.tar.gz":   "application/gzip",
+		"APP.ZIP":      "application/zip",
+		"app.deb":      "application/vnd.debian.binary-package",
+		"SHA256SUMS":   "text/plain",
+		"app":          "application/octet-stream",
+		"app.tar.zst":  "application/zstd",
+		"checksum.asc": "application/pgp-signature",
+	}
+	for name, want := range tests {
+		if got := ContentType(name); got != want {
+			t.Errorf("ContentType(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestChecksums(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"a.txt": "hello\n", "b.txt": ""})
+
+	got, err := Checksums([]Asset{
+		{Name: "a.txt", Path: filepath.Join(dir, "a.txt")},
+		{Name: "b.txt", Path: filepath.Join(dir, "b.txt")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03  a.txt\n" +
+		"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855  b.txt\n"
+	if string(got) != want {
+		t.Errorf("Checksums() =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,26 @@
+// Package branch derives release behavior from branch names.
+package branch
+
+import (
+	"regexp"
+
+	"github.com/netwarlan/action-semantic-versioning/internal/semver"
+)
+
+// maintenanceRegex matches maintenance branches such as "1.x", "1.2.x" or
+// "release/1.x", capturing the version range.
+var maintenanceRegex = regexp.MustCompile(`(?:^|/)(\d+\.(?:\d+\.)?x)$`)
+
+// Maintenance returns the version range of a maintenance branch, or false
+// if name is not a maintenance branch.
+func Maintenance(name string) (semver.Range, bool) {
+	m := maintenanceRegex.FindStringSubmatch(name)
+	if m == nil {
+		return semver.Range{}, false
+	}
+	r, err := semver.ParseRange(m[1])
+	if err != nil {
+		return semver.Range{}, false
+	}
+	return r, true
+}
@@ -0,0 +1,34 @@
+package branch
+
+import (
+	"testing"
+)
+
+func TestMaintenance(t *testing.T) {
+	tests := []struct {
+		name   string
+		want   string
+		wantOK bool
+	}{
+		{"1.x", "1.x", true},
+		{"1.2.x", "1.2.x", true},
+		{"release/2.x", "2.x", true},
+		{"maint/v1/1.4.x", "1.4.x", true},
+		{"main", "", false},
+		{"release/2.0", "", false},
+		{"feature/fix-1.x-bug", "", false},
+		{"v1.x", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, ok := Maintenance(tt.name)
+			if ok != tt.wantOK {
+				t.Fatalf("Maintenance(%q) ok = %v, want %v", tt.name, ok, tt.wantOK)
+			}
+			if ok && r.String() != tt.want {
+				t.Errorf("Maintenance(%q) = %s, want %s", tt.name, r, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package branch
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Kind is how a branch releases.
+type Kind int
+
+const (
+	// Stable releases regular versions.
+	Stable Kind = iota
+	// Prerelease releases numbered prereleases such as v1.2.0-beta.3.
+	Prerelease
+	// Preview computes a unique version such as v1.2.0-feat-xyz.3f2a1bc
+	// without tagging or releasing.
+	Preview
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Prerelease:
+		return "prerelease"
+	case Preview:
+		return "preview"
+	default:
+		return "stable"
+	}
+}
+
+// Channel is the release behavior selected for a branch.
+type Channel struct {
+	Kind Kind
+	// Identifier is the prerelease identifier for Prerelease and Preview
+	// channels, e.g. "beta" or "feat-xyz".
+	Identifier string
+}
+
+// Rule maps branches matching a glob pattern to a channel.
+type Rule struct {
+	Pattern    string
+	Kind       Kind
+	Identifier string // explicit prerelease identifier; defaults to the branch name
+}
+
+// Rules is an ordered list of branch rules; the first match wins.
+type Rules []Rule
+
+// ParseRules parses one rule per line in the form "pattern[: channel]",
+// where channel is "stable" (the default), "prerelease", "prerelease=id"
+// or "preview". Patterns use path.Match syntax, so "feature/*" matches
+// "feature/login". Blank lines and lines starting with "#" are ignored.
+func ParseRules(s string) (Rules, error) {
+	var rules Rules
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		pattern, channel, _ := strings.Cut(line, ":")
+		rule := Rule{Pattern: strings.TrimSpace(pattern)}
+		if _, err := path.Match(rule.Pattern, ""); err != nil || rule.Pattern == "" {
+			return nil, fmt.Errorf("invalid branch pattern in %q", line)
+		}
+
+		kind, id, _ := strings.Cut(strings.TrimSpace(channel), "=")
+		switch strings.ToLower(strings.TrimSpace(kind)) {
+		case "", "stable":
+			rule.Kind = Stable
+		case "prerelease":
+			rule.Kind = Prerelease
+		case "preview":
+			rule.Kind = Preview
+		default:
+			return nil, fmt.Errorf("invalid channel %q for branch %s: must be stable, prerelease or preview", kind, rule.Pattern)
+		}
+
+		rule.Identifier = strings.TrimSpace(id)
+		if rule.Identifier != "" && Slug(rule.Identifier) != rule.Identifier {
+			return nil, fmt.Errorf("invalid prerelease identifier %q for branch %s", rule.Identifier, rule.Pattern)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// Match returns the channel for the first rule matching name, or false if
+// no rule matches.
+func (rules Rules) Match(name string) (Channel, bool) {
+	for _, r := range rules {
+		if ok, _ := path.Match(r.Pattern, name); !ok {
+			continue
+		}
+		ch := Channel{Kind: r.Kind}
+		if r.Kind != Stable {
+			ch.Identifier = r.Identifier
+			if ch.Identifier == "" {
+				ch.Identifier = Slug(name)
+			}
+		}
+		return ch, true
+	}
+	return Channel{}, false
+}
+
+var slugInvalid = regexp.MustCompile(`[^0-9a-z-]+`)
+
+// Slug turns a branch name into a valid semver prerelease identifier,
+// e.g. "feat/XYZ_1" becomes "feat-xyz-1".
+func Slug(name string) string {
+	return strings.Trim(slugInvalid.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
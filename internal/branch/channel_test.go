@@ -0,0 +1,60 @@
+package branch
+
+import (
+	"testing"
+)
+
+func TestParseRulesAndMatch(t *testing.T) {
+	rules, err := ParseRules(`
+# release configuration
+main
+next: prerelease
+beta: prerelease=rc
+feat/*: preview
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		branch string
+		want   Channel
+		wantOK bool
+	}{
+		{"main", Channel{Kind: Stable}, true},
+		{"next", Channel{Kind: Prerelease, Identifier: "next"}, true},
+		{"beta", Channel{Kind: Prerelease, Identifier: "rc"}, true},
+		{"feat/Login_Page", Channel{Kind: Preview, Identifier: "feat-login-page"}, true},
+		{"feat/a/b", Channel{}, false},
+		{"develop", Channel{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.branch, func(t *testing.T) {
+			got, ok := rules.Match(tt.branch)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("Match(%q) = %+v, %v; want %+v, %v", tt.branch, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestParseRulesErrors(t *testing.T) {
+	for _, s := range []string{
+		"main: nightly",
+		"[: stable",
+		": stable",
+		"beta: prerelease=rc.1",
+	} {
+		if _, err := ParseRules(s); err == nil {
+			t.Errorf("ParseRules(%q) expected error", s)
+		}
+	}
+}
+
+func TestKindString(t *testing.T) {
+	for k, want := range map[Kind]string{Stable: "stable", Prerelease: "prerelease", Preview: "preview"} {
+		if got := k.String(); got != want {
+			t.Errorf("Kind(%d).String() = %q, want %q", k, got, want)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+// Package calver implements calendar versioning (https://calver.org) on top
+// of semver.Version. Formats have exactly three dot-separated segments, so
+// CalVer tags still parse, sort and filter as semantic versions.
+package calver
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/netwarlan/action-semantic-versioning/internal/commit"
+	"github.com/netwarlan/action-semantic-versioning/internal/semver"
+)
+
+type token int
+
+const (
+	tokenYYYY token = iota // full year: 2006, 2026
+	tokenYY                // short year: 6, 26, 106
+	token0Y                // zero-padded short year: 06, 26
+	tokenMM                // month: 1 ... 12
+	token0M                // zero-padded month: 01 ... 12
+	tokenWW                // ISO week: 1 ... 53
+	token0W                // zero-padded ISO week: 01 ... 53
+	tokenDD                // day: 1 ... 31
+	token0D                // zero-padded day: 01 ... 31
+	tokenMajor
+	tokenMinor
+	tokenMicro
+)
+
+var tokens = map[string]token{
+	"YYYY":  tokenYYYY,
+	"YY":    tokenYY,
+	"0Y":    token0Y,
+	"MM":    tokenMM,
+	"0M":    token0M,
+	"WW":    tokenWW,
+	"0W":    token0W,
+	"DD":    tokenDD,
+	"0D":    token0D,
+	"MAJOR": tokenMajor,
+	"MINOR": tokenMinor,
+	"MICRO": tokenMicro,
+	"PATCH": tokenMicro,
+}
+
+func (t token) isDate() bool {
+	return t < tokenMajor
+}
+
+func (t token) padded() bool {
+	return t == token0Y || t == token0M || t == token0W || t == token0D
+}
+
+// value returns t's value at now, with year as the year.
+func (t token) value(now time.Time, year int) int {
+	switch t {
+	case tokenYYYY:
+		return year
+	case tokenYY, token0Y:
+		return year - 2000
+	case tokenMM, token0M:
+		return int(now.Month())
+	case tokenWW, token0W:
+		_, week := now.ISOWeek()
+		return week
+	default:
+		return now.Day()
+	}
+}
+
+// Format is a parsed CalVer format such as "YYYY.MM.MICRO" or "YY.0M.PATCH".
+type Format struct {
+	segments [3]token
+	text     string
+}
+
+// ParseFormat parses a three-segment CalVer format. At least one segment
+// must be a date token and no token may repeat.
+func ParseFormat(s string) (Format, error) {
+	parts := strings.Split(s, ".")
+	if len(parts) != 3 {
+		return Format{}, fmt.Errorf("invalid calver format %q: must have three segments", s)
+	}
+
+	f := Format{text: s}
+	seen := map[token]bool{}
+	hasDate := false
+	for i, p := range parts {
+		t, ok := tokens[strings.ToUpper(p)]
+		if !ok {
+			return Format{}, fmt.Errorf("invalid calver format %q: unknown segment %q", s, p)
+		}
+		if seen[t] {
+			return Format{}, fmt.Errorf("invalid calver format %q: repeated segment %q", s, p)
+		}
+		seen[t] = true
+		hasDate = hasDate || t.isDate()
+		f.segments[i] = t
+	}
+	if !hasDate {
+		return Format{}, fmt.Errorf("invalid calver format %q: needs at least one date segment", s)
+	}
+	return f, nil
+}
+
+// String returns the format text.
+func (f Format) String() string {
+	return f.text
+}
+
+// value returns the value of date token t at now. In formats with a week,
+// the year is the ISO week's year, so that a week spanning New Year belongs
+// to a single year and versions never go backwards.
+func (f Format) value(t token, now time.Time) int {
+	year := now.Year()
+	for _, s := range f.segments {
+		if s == tokenWW || s == token0W {
+			year, _ = now.ISOWeek()
+		}
+	}
+	return t.value(now, year)
+}
+
+// Initial returns the first version for the period containing now.
+func (f Format) Initial(now time.Time) semver.Version {
+	var values [3]int
+	for i, t := range f.segments {
+		if t.isDate() {
+			values[i] = f.value(t, now)
+		}
+	}
+	return fromValues(values)
+}
+
+// Next returns the version after prev. If now is in a new period the date
+// segments are updated and counters reset to zero. Within the same period
+// the bump selects the counter to increment: major increments MAJOR, minor
+// increments MINOR, and anything else MICRO, falling back to another counter
+// when the format lacks that one. Less significant counters are reset.
+func (f Format) Next(prev semver.Version, bump commit.BumpType, now time.Time) (semver.Version, error) {
+	values := [3]int{prev.Major, prev.Minor, prev.Patch}
+
+	newPeriod := false
+	for i, t := range f.segments {
+		if t.isDate() && values[i] != f.value(t, now) {
+			newPeriod = true
+		}
+	}
+
+	var next semver.Version
+	if newPeriod {
+		next = f.Initial(now)
+	} else {
+		i, ok := f.counterFor(bump)
+		if !ok {
+			return semver.Version{}, fmt.Errorf("calver format %s has no counter to release %s again in the same period", f, prev)
+		}
+		values[i]++
+		for j := range f.segments {
+			if !f.segments[j].isDate() && f.segments[j] > f.segments[i] {
+				values[j] = 0
+			}
+		}
+		next = fromValues(values)
+	}
+
+	next.Prefix = prev.Prefix
+	next.Suffix = prev.Suffix
+	if next.Compare(semver.Version{Major: prev.Major, Minor: prev.Minor, Patch: prev.Patch}) <= 0 {
+		return semver.Version{}, fmt.Errorf("calver version %s for %s is not after %s", f.Render(next), now.Format("2006-01-02"), f.Render(prev))
+	}
+	return next, nil
+}
+
+// counterFor returns the index of the counter segment a bump increments.
+func (f Format) counterFor(bump commit.BumpType) (int, bool) {
+	candidates := []token{tokenMicro, tokenMinor, tokenMajor}
+	switch bump {
+	case commit.BumpMajor:
+		candidates = []token{tokenMajor, tokenMinor, tokenMicro}
+	case commit.BumpMinor:
+		candidates = []token{tokenMinor, tokenMicro, tokenMajor}
+	}
+	for _, c := range candidates {
+		for i, t := range f.segments {
+			if t == c {
+				return i, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// Render formats v with the format's zero padding, e.g. "v26.01.3".
+func (f Format) Render(v semver.Version) string {
+	values := [3]int{v.Major, v.Minor, v.Patch}
+	parts := make([]string, 3)
+	for i, t := range f.segments {
+		if t.padded() {
+			parts[i] = fmt.Sprintf("%02d", values[i])
+		} else {
+			parts[i] = fmt.Sprintf("%d", values[i])
+		}
+	}
+
+	s := v.Prefix + strings.Join(parts, ".")
+	if v.Prerelease != "" {
+		s += "-" + v.Prerelease
+	}
+	if v.Metadata != "" {
+		s += "+" + v.Metadata
+	}
+	return s + v.Suffix
+}
+
+func fromValues(values [3]int) semver.Version {
+	return semver.Version{Major: values[0], Minor: values[1], Patch: values[2]}
+}
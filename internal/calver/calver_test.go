@@ -0,0 +1,96 @@
+package calver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/netwarlan/action-semantic-versioning/internal/commit"
+	"github.com/netwarlan/action-semantic-versioning/internal/semver"
+)
+
+func date(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 12, 0, 0, 0, time.UTC)
+}
+
+func TestParseFormat(t *testing.T) {
+	for _, s := range []string{"YYYY.MM.MICRO", "YY.0M.PATCH", "YYYY.0W.MICRO", "YYYY.MAJOR.MINOR", "0Y.0M.0D"} {
+		if _, err := ParseFormat(s); err != nil {
+			t.Errorf("ParseFormat(%q) error: %v", s, err)
+		}
+	}
+	for _, s := range []string{"YYYY.MM", "YYYY.MM.DD.MICRO", "YYYY.MM.BUILD", "MAJOR.MINOR.MICRO", "YYYY.YYYY.MICRO"} {
+		if _, err := ParseFormat(s); err == nil {
+			t.Errorf("ParseFormat(%q) expected error", s)
+		}
+	}
+}
+
+func TestInitialAndRender(t *testing.T) {
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"YYYY.MM.MICRO", "2026.1.0"},
+		{"YY.0M.PATCH", "26.01.0"},
+		{"0Y.0M.0D", "26.01.05"},
+		{"YYYY.0W.MICRO", "2026.02.0"},
+	}
+	for _, tt := range tests {
+		f, err := ParseFormat(tt.format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := f.Render(f.Initial(date(2026, time.January, 5))); got != tt.want {
+			t.Errorf("%s: Initial() = %s, want %s", tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		prev    string
+		bump    commit.BumpType
+		now     time.Time
+		want    string
+		wantErr bool
+	}{
+		{"same period", "YYYY.MM.MICRO", "v2026.10.2", commit.BumpPatch, date(2026, time.October, 18), "v2026.10.3", false},
+		{"new month", "YYYY.MM.MICRO", "v2026.9.7", commit.BumpMinor, date(2026, time.October, 1), "v2026.10.0", false},
+		{"new year", "YY.0M.PATCH", "26.12.4", commit.BumpPatch, date(2027, time.January, 2), "27.01.0", false},
+		{"padded same period", "YY.0M.PATCH", "26.01.4", commit.BumpMajor, date(2026, time.January, 20), "26.01.5", false},
+		{"minor counter", "YYYY.MINOR.MICRO", "2026.3.4", commit.BumpMinor, date(2026, time.May, 1), "2026.4.0", false},
+		{"micro counter", "YYYY.MINOR.MICRO", "2026.3.4", commit.BumpPatch, date(2026, time.May, 1), "2026.3.5", false},
+		{"no counter", "YYYY.0M.0D", "2026.10.18", commit.BumpPatch, date(2026, time.October, 18), "", true},
+		{"week spanning new year", "YYYY.WW.MICRO", "2026.52.3", commit.BumpPatch, date(2027, time.January, 1), "2026.53.0", false},
+		{"first week of year", "YYYY.WW.MICRO", "2026.53.0", commit.BumpPatch, date(2027, time.January, 5), "2027.1.0", false},
+		{"clock behind", "YYYY.MM.MICRO", "2026.10.0", commit.BumpPatch, date(2026, time.September, 30), "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := ParseFormat(tt.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			prev, err := semver.Parse(tt.prev)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := f.Next(prev, tt.bump, tt.now)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %s", f.Render(got))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r := f.Render(got); r != tt.want {
+				t.Errorf("Next() = %s, want %s", r, tt.want)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package changelog
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/netwarlan/action-semantic-versioning/internal/commit"
 )
@@ -50,6 +51,60 @@ func Generate(commits []commit.ConventionalCommit, previousTag, newTag string) s
 	return sb.String()
 }
 
+// Prepend adds a release entry for tag to the contents of a CHANGELOG.md
+// file, newest first, below the file's title. body is the output of
+// Generate; its "What's Changed" heading is replaced by the tag and date.
+func Prepend(existing, tag string, date time.Time, body string) string {
+	entry := fmt.Sprintf("## %s (%s)\n", tag, date.Format("2006-01-02"))
+	entry += strings.TrimPrefix(body, "## What's Changed\n")
+	entry = strings.TrimRight(entry, "\n") + "\n"
+
+	title := "# Changelog\n"
+	rest := existing
+	if strings.HasPrefix(existing, "# ") {
+		title, rest, _ = strings.Cut(existing, "\n")
+		title += "\n"
+	} else if strings.TrimSpace(existing) != "" {
+		title = ""
+	}
+	rest = strings.TrimLeft(rest, "\n")
+
+	out := title
+	if out != "" {
+		out += "\n"
+	}
+	out += entry
+	if rest != "" {
+		out += "\n" + rest
+	}
+	return out
+}
+
+// Merge appends release notes from GitHub's generate-notes API to the output
+// of Generate, under the shared "What's Changed" heading. Uncategorised pull
+// requests get a heading of their own. A single Full Changelog link ends
+// the result, the notes' one if they have it.
+func Merge(generated, notes string) string {
+	notes = strings.ReplaceAll(notes, "\r\n", "\n")
+	notes = strings.TrimLeft(strings.TrimPrefix(strings.TrimLeft(notes, "\n"), "## What's Changed\n"), "\n")
+	if strings.TrimSpace(notes) == "" {
+		return generated
+	}
+	if strings.HasPrefix(notes, "* ") || strings.HasPrefix(notes, "- ") {
+		notes = "### Pull Requests\n" + notes
+	}
+
+	var link string
+	if i := strings.Index(generated, "\n**Full Changelog**"); i >= 0 {
+		generated, link = generated[:i], generated[i:]
+	}
+	out := strings.TrimRight(generated, "\n") + "\n\n" + strings.TrimRight(notes, "\n") + "\n"
+	if link != "" && !strings.Contains(notes, "**Full Changelog**") {
+		out += link
+	}
+	return out
+}
+
 func formatCommit(c commit.ConventionalCommit) string {
 	hash := shortHash(c.Hash)
 	if c.Scope != "" {
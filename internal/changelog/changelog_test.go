@@ -3,6 +3,7 @@ package changelog
 import (
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/netwarlan/action-semantic-versioning/internal/commit"
 )
@@ -92,3 +93,55 @@ func TestGenerateOmitsEmptySections(t *testing.T) {
 		t.Error("Bug Fixes section should be present")
 	}
 }
+
+func TestPrepend(t *testing.T) {
+	date := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	body := "## What's Changed\n\n### Features\n- add login (abc1234)\n\n**Full Changelog**: v1.2.0...v1.3.0\n"
+	entry := "## v1.3.0 (2026-10-18)\n\n### Features\n- add login (abc1234)\n\n**Full Changelog**: v1.2.0...v1.3.0\n"
+
+	tests := []struct {
+		name     string
+		existing string
+		want     string
+	}{
+		{"new file", "", "# Changelog\n\n" + entry},
+		{"with title", "# Changelog\n\n## v1.2.0 (2026-09-01)\n- old\n", "# Changelog\n\n" + entry + "\n## v1.2.0 (2026-09-01)\n- old\n"},
+		{"without title", "## v1.2.0 (2026-09-01)\n- old\n", entry + "\n## v1.2.0 (2026-09-01)\n- old\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Prepend(tt.existing, "v1.3.0", date, body); got != tt.want {
+				t.Errorf("Prepend() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMerge(t *testing.T) {
+	generated := "## What's Changed\n\n### Features\n- add login (abc1234)\n\n**Full Changelog**: v1.2.0...v1.3.0\n"
+
+	tests := []struct {
+		name  string
+		notes string
+		want  string
+	}{
+		{
+			"uncategorised",
+			"## What's Changed\r\n* Add login by @octocat in https://github.com/o/r/pull/1\r\n\r\n\r\n**Full Changelog**: https://github.com/o/r/compare/v1.2.0...v1.3.0",
+			"## What's Changed\n\n### Features\n- add login (abc1234)\n\n### Pull Requests\n* Add login by @octocat in https://github.com/o/r/pull/1\n\n\n**Full Changelog**: https://github.com/o/r/compare/v1.2.0...v1.3.0\n",
+		},
+		{
+			"categorised",
+			"## What's Changed\n### Exciting Features 🎉\n* Add login by @octocat in #1\n## New Contributors\n* @octocat made their first contribution in #1\n",
+			"## What's Changed\n\n### Features\n- add login (abc1234)\n\n### Exciting Features 🎉\n* Add login by @octocat in #1\n## New Contributors\n* @octocat made their first contribution in #1\n\n**Full Changelog**: v1.2.0...v1.3.0\n",
+		},
+		{"empty", "", generated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Merge(generated, tt.notes); got != tt.want {
+				t.Errorf("Merge() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package commit
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// skipReleaseMarkers are the message directives that exclude a commit from
+// version bump calculation.
+var skipReleaseMarkers = []string{"[skip release]", "[release skip]"}
+
+// SkipsRelease reports whether the commit message contains a [skip release]
+// directive. Such commits never contribute to the version bump.
+func (c ConventionalCommit) SkipsRelease() bool {
+	raw := strings.ToLower(c.Raw)
+	for _, m := range skipReleaseMarkers {
+		if strings.Contains(raw, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// BumpOverride returns the bump requested by a "Bump:" footer, which
+// replaces whatever the commit type would otherwise imply. Unrecognized
+// values are ignored.
+func (c ConventionalCommit) BumpOverride() (BumpType, bool) {
+	for _, f := range c.Footers {
+		if !strings.EqualFold(f.Token, "Bump") {
+			continue
+		}
+		if b, err := ParseBumpType(f.Value); err == nil {
+			return b, true
+		}
+	}
+	return BumpNone, false
+}
+
+// closingTokens are the footer tokens GitHub treats as closing an issue.
+var closingTokens = []string{"close", "closes", "closed", "fix", "fixes", "fixed", "resolve", "resolves", "resolved"}
+
+// issueRefRegex matches "#N" references to issues in the same repository,
+// but not bare numbers or references to other repositories like
+// "org/repo#1".
+var issueRefRegex = regexp.MustCompile(`(?:^|[\s,])#(\d+)\b`)
+
+// ClosedIssues returns the issue numbers named by "Closes #N", "Fixes #N"
+// and similar footers, in order and without duplicates. A footer may list
+// several issues, e.g. "Fixes #1, #2".
+func (c ConventionalCommit) ClosedIssues() []int {
+	var issues []int
+	seen := map[int]bool{}
+	for _, f := range c.Footers {
+		if !slices.Contains(closingTokens, strings.ToLower(f.Token)) {
+			continue
+		}
+		value := f.Value
+		if f.Separator == "#" {
+			value = "#" + value
+		}
+		for _, m := range issueRefRegex.FindAllStringSubmatch(value, -1) {
+			if n, err := strconv.Atoi(m[1]); err == nil && !seen[n] {
+				seen[n] = true
+				issues = append(issues, n)
+			}
+		}
+	}
+	return issues
+}
+
+// ReleaseAs returns the version requested by the newest "Release-As:" footer
+// in commits, which are expected in git log order (newest first). It returns
+// an empty string if no commit requests an explicit version.
+func ReleaseAs(commits []ConventionalCommit) string {
+	for _, c := range commits {
+		if c.SkipsRelease() {
+			continue
+		}
+		for _, f := range c.Footers {
+			if strings.EqualFold(f.Token, "Release-As") && f.Value != "" {
+				return f.Value
+			}
+		}
+	}
+	return ""
+}
+
+// ParseBumpType parses "major", "minor", "patch" or "none" (case-insensitive).
+func ParseBumpType(s string) (BumpType, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "major":
+		return BumpMajor, nil
+	case "minor":
+		return BumpMinor, nil
+	case "patch":
+		return BumpPatch, nil
+	case "none":
+		return BumpNone, nil
+	default:
+		return BumpNone, fmt.Errorf("invalid bump type %q", s)
+	}
+}
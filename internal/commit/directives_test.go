@@ -0,0 +1,99 @@
+package commit
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSkipsRelease(t *testing.T) {
+	tests := []struct {
+		message string
+		want    bool
+	}{
+		{"fix: typo [skip release]", true},
+		{"feat: add thing\n\nNot ready yet. [Release Skip]", true},
+		{"fix: typo [skip ci]", false},
+		{"fix: typo", false},
+	}
+	for _, tt := range tests {
+		if got := Parse("abc", tt.message).SkipsRelease(); got != tt.want {
+			t.Errorf("SkipsRelease(%q) = %v, want %v", tt.message, got, tt.want)
+		}
+	}
+}
+
+func TestDetermineBumpDirectives(t *testing.T) {
+	tests := []struct {
+		name     string
+		messages []string
+		want     BumpType
+	}{
+		{"skip release ignored", []string{"feat: add thing [skip release]", "fix: bug"}, BumpPatch},
+		{"all skipped", []string{"feat!: redesign [skip release]"}, BumpNone},
+		{"bump footer raises", []string{"chore: marketing\n\nBump: minor"}, BumpMinor},
+		{"bump footer lowers", []string{"feat!: rename flag\n\nBump: patch"}, BumpPatch},
+		{"bump none", []string{"feat: internal only\n\nbump: none"}, BumpNone},
+		{"bump footer per commit", []string{"fix: bug\n\nBump: major", "feat: thing"}, BumpMajor},
+		{"invalid bump ignored", []string{"feat: thing\n\nBump: huge"}, BumpMinor},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var commits []ConventionalCommit
+			for _, m := range tt.messages {
+				commits = append(commits, Parse("abc", m))
+			}
+			if got := DetermineBump(commits, false); got != tt.want {
+				t.Errorf("DetermineBump() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReleaseAs(t *testing.T) {
+	commits := []ConventionalCommit{
+		Parse("c", "chore: release\n\nRelease-As: 3.0.0 [skip release]"),
+		Parse("b", "chore: release\n\nRelease-As: 2.0.0"),
+		Parse("a", "chore: release\n\nRelease-As: 1.5.0"),
+	}
+	if got := ReleaseAs(commits); got != "2.0.0" {
+		t.Errorf("ReleaseAs() = %q, want 2.0.0", got)
+	}
+	if got := ReleaseAs([]ConventionalCommit{Parse("a", "fix: bug")}); got != "" {
+		t.Errorf("ReleaseAs() = %q, want empty", got)
+	}
+}
+
+func TestParseBumpType(t *testing.T) {
+	for _, b := range []BumpType{BumpNone, BumpPatch, BumpMinor, BumpMajor} {
+		got, err := ParseBumpType(b.String())
+		if err != nil || got != b {
+			t.Errorf("ParseBumpType(%q) = %v, %v", b.String(), got, err)
+		}
+	}
+	if _, err := ParseBumpType("huge"); err == nil {
+		t.Error("expected error for invalid bump type")
+	}
+}
+
+func TestClosedIssues(t *testing.T) {
+	tests := []struct {
+		message string
+		want    []int
+	}{
+		{"fix: crash\n\nFixes #12", []int{12}},
+		{"fix: crash\n\nCloses: #3\nResolves #4, #5\nRefs #6", []int{3, 4, 5}},
+		{"feat: thing\n\nfixes #7\nFixed #7", []int{7}},
+		{"fix: crash\n\nFixes other/repo#8", nil},
+		{"fix: crash (#9)", nil},
+		{"fix: crash", nil},
+		{"fix: crash\n\nFixes #12 after 5 retries", []int{12}},
+		{"fix: crash\n\nCloses #4\n  seen in 2 builds", []int{4}},
+		{"fix: crash\n\nFixes: the 3 crashes", nil},
+	}
+	for _, tt := range tests {
+		got := Parse("abc", tt.message).ClosedIssues()
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("ClosedIssues(%q) = %v, want %v", tt.message, got, tt.want)
+		}
+	}
+}
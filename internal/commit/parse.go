@@ -7,12 +7,22 @@ import (
 
 var (
 	subjectRegex = regexp.MustCompile(`^(\w+)(\(([^)]*)\))?(!)?:\s*(.+)$`)
-	footerRegex  = regexp.MustCompile(`^([\w-]+|BREAKING CHANGE)\s*:\s*(.+)$`)
+	// footerRegex matches the first line of a footer: a word token followed by
+	// either a ": " or " #" separator, per the Conventional Commits spec.
+	footerRegex = regexp.MustCompile(`^([\w-]+|BREAKING CHANGE)(?:[ \t]*:[ \t]+|( #))(.*)$`)
 )
 
 // Parse parses a commit message into a ConventionalCommit.
 // Non-conventional messages return a ConventionalCommit with an empty Type.
 func Parse(hash, message string) ConventionalCommit {
+	return ConventionalParser{}.Parse(hash, message)
+}
+
+// parseMessage splits message into subject, body and footers. parseSubject
+// fills in the type, scope, description and breaking flag from the subject
+// line and reports whether it matched the convention; if not, only Hash and
+// Raw are set.
+func parseMessage(hash, message string, parseSubject func(cc *ConventionalCommit, subject string) bool) ConventionalCommit {
 	cc := ConventionalCommit{
 		Hash: hash,
 		Raw:  message,
@@ -23,18 +33,10 @@ func Parse(hash, message string) ConventionalCommit {
 		return cc
 	}
 
-	matches := subjectRegex.FindStringSubmatch(strings.TrimSpace(lines[0]))
-	if matches == nil {
+	if !parseSubject(&cc, strings.TrimSpace(lines[0])) {
 		return cc
 	}
 
-	cc.Type = strings.ToLower(matches[1])
-	cc.Scope = matches[3]
-	if matches[4] == "!" {
-		cc.Breaking = true
-	}
-	cc.Description = matches[5]
-
 	// Parse body and footers from remaining lines.
 	if len(lines) > 1 {
 		cc.Body, cc.Footers = parseBodyAndFooters(lines[1:])
@@ -42,8 +44,7 @@ func Parse(hash, message string) ConventionalCommit {
 
 	// Check footers for BREAKING CHANGE.
 	for _, f := range cc.Footers {
-		token := strings.ToUpper(f.Token)
-		if token == "BREAKING CHANGE" || token == "BREAKING-CHANGE" {
+		if isBreakingToken(f.Token) {
 			cc.Breaking = true
 		}
 	}
@@ -51,66 +52,124 @@ func Parse(hash, message string) ConventionalCommit {
 	return cc
 }
 
-func parseBodyAndFooters(lines []string) (string, []Footer) {
-	// Find the start of the footer section by scanning from the end.
-	// Footers are contiguous lines at the end that match the footer pattern.
-	footerStart := len(lines)
-	for i := len(lines) - 1; i >= 0; i-- {
-		trimmed := strings.TrimSpace(lines[i])
-		if trimmed == "" {
-			// Blank line before footers — this is the separator.
-			break
+func isBreakingToken(token string) bool {
+	token = strings.ToUpper(token)
+	return token == "BREAKING CHANGE" || token == "BREAKING-CHANGE"
+}
+
+// footerStart returns the index of the first footer line in lines. As with
+// git trailers, the footers are the last paragraph if it opens with a
+// footer token. A BREAKING CHANGE footer may open an earlier paragraph, since
+// its note can span several paragraphs.
+func footerStart(lines []string) int {
+	last, breaking := -1, -1
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || (i > 0 && strings.TrimSpace(lines[i-1]) != "") {
+			continue
 		}
-		if footerRegex.MatchString(trimmed) {
-			footerStart = i
-		} else {
-			break
+		last = -1
+		if m := footerRegex.FindStringSubmatch(trimmed); m != nil {
+			last = i
+			if breaking < 0 && isBreakingToken(m[1]) {
+				breaking = i
+			}
 		}
 	}
+	switch {
+	case breaking >= 0:
+		return breaking
+	case last >= 0:
+		return last
+	default:
+		return len(lines)
+	}
+}
+
+func parseBodyAndFooters(lines []string) (string, []Footer) {
+	start := footerStart(lines)
 
 	var footers []Footer
-	for _, line := range lines[footerStart:] {
+	var values [][]string
+	// target is the footer that continuation lines belong to. Only a
+	// BREAKING CHANGE note runs on across blank lines.
+	target, breaking := -1, -1
+	for _, line := range lines[start:] {
+		// Lines indented with whitespace are git trailer continuation lines
+		// and never start a new footer.
+		continued := line != "" && (line[0] == ' ' || line[0] == '\t')
 		trimmed := strings.TrimSpace(line)
-		if m := footerRegex.FindStringSubmatch(trimmed); m != nil {
-			footers = append(footers, Footer{Token: m[1], Value: m[2]})
+		if m := footerRegex.FindStringSubmatch(trimmed); m != nil && !continued {
+			sep := ":"
+			if m[2] != "" {
+				sep = "#"
+			}
+			footers = append(footers, Footer{Token: m[1], Separator: sep})
+			values = append(values, []string{strings.TrimSpace(m[3])})
+			target = len(footers) - 1
+			if isBreakingToken(m[1]) {
+				breaking = target
+			}
+			continue
+		}
+		if trimmed == "" {
+			target = breaking
+		}
+		if target >= 0 {
+			values[target] = append(values[target], trimmed)
 		}
 	}
+	for i := range footers {
+		footers[i].Value = strings.TrimSpace(strings.Join(values[i], "\n"))
+	}
 
 	// Body is everything between the blank line after the subject and the footer section.
-	bodyLines := lines[:footerStart]
-	body := strings.TrimSpace(strings.Join(bodyLines, "\n"))
+	body := strings.TrimSpace(strings.Join(lines[:start], "\n"))
 
 	return body, footers
 }
 
 // DetermineBump determines the highest bump type from a list of commits.
+// Commits marked [skip release] are ignored, and a "Bump:" footer replaces
+// the bump implied by its commit's type.
 func DetermineBump(commits []ConventionalCommit, bumpPatchOnUnknown bool) BumpType {
 	bump := BumpNone
 
 	for _, c := range commits {
-		if c.Breaking {
-			return BumpMajor
+		if c.SkipsRelease() {
+			continue
 		}
-
-		switch c.Type {
-		case "feat":
-			if bump < BumpMinor {
-				bump = BumpMinor
-			}
-		case "fix", "perf":
-			if bump < BumpPatch {
-				bump = BumpPatch
-			}
-		default:
-			if bumpPatchOnUnknown && c.Type != "" && bump < BumpPatch {
-				bump = BumpPatch
-			}
-			// Non-conventional commits (empty Type) only bump if bumpPatchOnUnknown.
-			if bumpPatchOnUnknown && c.Type == "" && bump < BumpPatch {
-				bump = BumpPatch
-			}
+		if b := commitBump(c, bumpPatchOnUnknown); b > bump {
+			bump = b
+		}
+		if bump == BumpMajor {
+			return BumpMajor
 		}
 	}
 
 	return bump
 }
+
+func commitBump(c ConventionalCommit, bumpPatchOnUnknown bool) BumpType {
+	if b, ok := c.BumpOverride(); ok {
+		return b
+	}
+
+	if c.Breaking {
+		return BumpMajor
+	}
+
+	switch c.Type {
+	case "feat":
+		return BumpMinor
+	case "fix", "perf":
+		return BumpPatch
+	default:
+		// Other types and non-conventional commits (empty Type) only bump
+		// if bumpPatchOnUnknown.
+		if bumpPatchOnUnknown {
+			return BumpPatch
+		}
+		return BumpNone
+	}
+}
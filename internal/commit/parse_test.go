@@ -87,6 +87,76 @@ BREAKING-CHANGE: new response format`
 	}
 }
 
+func TestParseMultiParagraphBreakingChange(t *testing.T) {
+	msg := `feat: new config loader
+
+BREAKING CHANGE: the config format changed from YAML to TOML.
+
+Existing files must be converted with the migrate command.
+Refs #123
+Reviewed-by: Alice`
+
+	cc := Parse("abc", msg)
+	if !cc.Breaking {
+		t.Error("expected Breaking to be true")
+	}
+	if cc.Body != "" {
+		t.Errorf("Body = %q, want empty", cc.Body)
+	}
+	if len(cc.Footers) != 3 {
+		t.Fatalf("expected 3 footers, got %d: %+v", len(cc.Footers), cc.Footers)
+	}
+	want := "the config format changed from YAML to TOML.\n\nExisting files must be converted with the migrate command."
+	if cc.Footers[0].Value != want {
+		t.Errorf("footer[0].Value = %q, want %q", cc.Footers[0].Value, want)
+	}
+	if f := cc.Footers[1]; f.Token != "Refs" || f.Value != "123" || f.Separator != "#" {
+		t.Errorf("footer[1] = %+v", f)
+	}
+	if f := cc.Footers[2]; f.Token != "Reviewed-by" || f.Value != "Alice" || f.Separator != ":" {
+		t.Errorf("footer[2] = %+v", f)
+	}
+}
+
+func TestParseTrailerContinuationLines(t *testing.T) {
+	msg := "fix: handle timeouts\n\nSigned-off-by: Alice <alice@example.com>\nNote: first line\n  Token: still part of the note\nAcked-by: Bob"
+
+	cc := Parse("abc", msg)
+	if len(cc.Footers) != 3 {
+		t.Fatalf("expected 3 footers, got %d: %+v", len(cc.Footers), cc.Footers)
+	}
+	if got := cc.Footers[1].Value; got != "first line\nToken: still part of the note" {
+		t.Errorf("footer[1].Value = %q", got)
+	}
+	if cc.Footers[2].Token != "Acked-by" {
+		t.Errorf("footer[2].Token = %q", cc.Footers[2].Token)
+	}
+}
+
+func TestParseBodyNotMistakenForFooter(t *testing.T) {
+	msg := "fix: correct redirect\n\nSee https://example.com/issue for details.\n\nhttps://example.com/other"
+
+	cc := Parse("abc", msg)
+	if len(cc.Footers) != 0 {
+		t.Errorf("expected no footers, got %+v", cc.Footers)
+	}
+	if cc.Body != "See https://example.com/issue for details.\n\nhttps://example.com/other" {
+		t.Errorf("Body = %q", cc.Body)
+	}
+}
+
+func TestParseBodyParagraphWithColon(t *testing.T) {
+	msg := "fix: x\n\nSteps:\n1. do y\n\nNote: only on Linux.\n\nReviewed-by: Z"
+
+	cc := Parse("abc", msg)
+	if cc.Body != "Steps:\n1. do y\n\nNote: only on Linux." {
+		t.Errorf("Body = %q", cc.Body)
+	}
+	if len(cc.Footers) != 1 || cc.Footers[0].Token != "Reviewed-by" || cc.Footers[0].Value != "Z" {
+		t.Errorf("Footers = %+v", cc.Footers)
+	}
+}
+
 func TestDetermineBump(t *testing.T) {
 	tests := []struct {
 		name               string
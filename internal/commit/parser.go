@@ -0,0 +1,173 @@
+package commit
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Parser parses raw commit messages according to a commit convention.
+// Messages that do not follow the convention return a ConventionalCommit
+// with an empty Type.
+type Parser interface {
+	Parse(hash, message string) ConventionalCommit
+}
+
+// NewParser returns the parser for the named convention: "conventional"
+// (the default), "angular", "gitmoji" or "regex". pattern is only used by
+// the regex convention.
+func NewParser(convention, pattern string) (Parser, error) {
+	switch strings.ToLower(convention) {
+	case "", "conventional":
+		return ConventionalParser{}, nil
+	case "angular":
+		return AngularParser{}, nil
+	case "gitmoji":
+		return GitmojiParser{}, nil
+	case "regex":
+		return NewRegexParser(pattern)
+	default:
+		return nil, fmt.Errorf("unknown commit convention %q", convention)
+	}
+}
+
+// ConventionalParser parses Conventional Commits: "type(scope)!: description".
+type ConventionalParser struct{}
+
+// Parse implements Parser.
+func (ConventionalParser) Parse(hash, message string) ConventionalCommit {
+	return parseMessage(hash, message, func(cc *ConventionalCommit, subject string) bool {
+		m := subjectRegex.FindStringSubmatch(subject)
+		if m == nil {
+			return false
+		}
+		cc.Type = strings.ToLower(m[1])
+		cc.Scope = m[3]
+		cc.Breaking = m[4] == "!"
+		cc.Description = m[5]
+		return true
+	})
+}
+
+var angularSubjectRegex = regexp.MustCompile(`^(build|ci|docs|feat|fix|perf|refactor|style|test|revert)(?:\(([^)]*)\))?: (.+)$`)
+
+// AngularParser parses the stricter Angular commit convention: a fixed set
+// of lowercase types, no "!" marker, and breaking changes declared only
+// through a BREAKING CHANGE footer.
+type AngularParser struct{}
+
+// Parse implements Parser.
+func (AngularParser) Parse(hash, message string) ConventionalCommit {
+	return parseMessage(hash, message, func(cc *ConventionalCommit, subject string) bool {
+		m := angularSubjectRegex.FindStringSubmatch(subject)
+		if m == nil {
+			return false
+		}
+		cc.Type = m[1]
+		cc.Scope = m[2]
+		cc.Description = m[3]
+		return true
+	})
+}
+
+// gitmojiTypes maps gitmoji shortcodes and their emoji to commit types.
+// Emoji are stored without the U+FE0F variation selector.
+var gitmojiTypes = map[string]string{
+	":sparkles:": "feat", "✨": "feat",
+	":boom:": "feat", "💥": "feat",
+	":bug:": "fix", "🐛": "fix",
+	":ambulance:": "fix", "🚑": "fix",
+	":lock:": "fix", "🔒": "fix",
+	":adhesive_bandage:": "fix", "🩹": "fix",
+	":zap:": "perf", "⚡": "perf",
+	":memo:": "docs", "📝": "docs",
+	":pencil2:": "docs", "✏": "docs",
+	":art:": "style", "🎨": "style",
+	":rotating_light:": "style", "🚨": "style",
+	":recycle:": "refactor", "♻": "refactor",
+	":fire:": "refactor", "🔥": "refactor",
+	":white_check_mark:": "test", "✅": "test",
+	":test_tube:": "test", "🧪": "test",
+	":construction_worker:": "ci", "👷": "ci",
+	":green_heart:": "ci", "💚": "ci",
+	":package:": "build", "📦": "build",
+	":heavy_plus_sign:": "build", "➕": "build",
+	":heavy_minus_sign:": "build", "➖": "build",
+	":arrow_up:": "build", "⬆": "build",
+	":arrow_down:": "build", "⬇": "build",
+	":wrench:": "chore", "🔧": "chore",
+	":bookmark:": "chore", "🔖": "chore",
+	":rewind:": "revert", "⏪": "revert",
+}
+
+var gitmojiSubjectRegex = regexp.MustCompile(`^(:[a-z0-9_+-]+:|\S+?)\s*(?:\(([^)]*)\):?)?\s+(.+)$`)
+
+// GitmojiParser parses gitmoji commits such as ":sparkles: add login" or
+// "🐛 (api) handle nil body". :boom: / 💥 marks a breaking change.
+type GitmojiParser struct{}
+
+// Parse implements Parser.
+func (GitmojiParser) Parse(hash, message string) ConventionalCommit {
+	return parseMessage(hash, message, func(cc *ConventionalCommit, subject string) bool {
+		m := gitmojiSubjectRegex.FindStringSubmatch(subject)
+		if m == nil {
+			return false
+		}
+		emoji := strings.ReplaceAll(m[1], "\uFE0F", "")
+		typ, ok := gitmojiTypes[emoji]
+		if !ok {
+			return false
+		}
+		cc.Type = typ
+		cc.Scope = m[2]
+		cc.Breaking = emoji == ":boom:" || emoji == "💥"
+		cc.Description = m[3]
+		return true
+	})
+}
+
+// RegexParser parses subjects with a user-supplied regular expression.
+// The "type" and "description" named groups are required; "scope" and
+// "breaking" are optional, and a non-empty "breaking" match marks the
+// commit as breaking.
+type RegexParser struct {
+	re *regexp.Regexp
+}
+
+// NewRegexParser compiles pattern and checks it has the required groups.
+func NewRegexParser(pattern string) (*RegexParser, error) {
+	if pattern == "" {
+		return nil, fmt.Errorf("regex commit convention requires a pattern")
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid commit pattern: %w", err)
+	}
+	for _, group := range []string{"type", "description"} {
+		if re.SubexpIndex(group) < 0 {
+			return nil, fmt.Errorf("commit pattern must have a named group %q", group)
+		}
+	}
+	return &RegexParser{re: re}, nil
+}
+
+// Parse implements Parser.
+func (p *RegexParser) Parse(hash, message string) ConventionalCommit {
+	return parseMessage(hash, message, func(cc *ConventionalCommit, subject string) bool {
+		m := p.re.FindStringSubmatch(subject)
+		if m == nil {
+			return false
+		}
+		group := func(name string) string {
+			if i := p.re.SubexpIndex(name); i >= 0 {
+				return m[i]
+			}
+			return ""
+		}
+		cc.Type = strings.ToLower(group("type"))
+		cc.Scope = group("scope")
+		cc.Breaking = group("breaking") != ""
+		cc.Description = group("description")
+		return cc.Type != ""
+	})
+}
@@ -0,0 +1,103 @@
+package commit
+
+import (
+	"testing"
+)
+
+func TestGitmojiParser(t *testing.T) {
+	tests := []struct {
+		name      string
+		message   string
+		wantType  string
+		wantScope string
+		wantDesc  string
+		wantBreak bool
+	}{
+		{"shortcode", ":sparkles: add login", "feat", "", "add login", false},
+		{"emoji", "✨ add login", "feat", "", "add login", false},
+		{"variation selector", "⚡️ speed up parser", "perf", "", "speed up parser", false},
+		{"scoped", "🐛 (api): handle nil body", "fix", "api", "handle nil body", false},
+		{"breaking", ":boom: drop v1 endpoints", "feat", "", "drop v1 endpoints", true},
+		{"unknown emoji", "🦄 something", "", "", "", false},
+		{"plain text", "Update README", "", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cc := GitmojiParser{}.Parse("abc", tt.message)
+			if cc.Type != tt.wantType || cc.Scope != tt.wantScope || cc.Description != tt.wantDesc || cc.Breaking != tt.wantBreak {
+				t.Errorf("Parse(%q) = %+v", tt.message, cc)
+			}
+		})
+	}
+}
+
+func TestGitmojiParserBreakingFooter(t *testing.T) {
+	cc := GitmojiParser{}.Parse("abc", ":recycle: rework config\n\nBREAKING CHANGE: keys renamed")
+	if cc.Type != "refactor" || !cc.Breaking {
+		t.Errorf("Parse() = %+v", cc)
+	}
+}
+
+func TestAngularParser(t *testing.T) {
+	tests := []struct {
+		message   string
+		wantType  string
+		wantBreak bool
+	}{
+		{"feat(core): add signals", "feat", false},
+		{"fix: handle nil", "fix", false},
+		{"feat!: redesign", "", false},
+		{"chore: bump deps", "", false},
+		{"Feat: capitalized", "", false},
+		{"refactor: rename\n\nBREAKING CHANGE: renamed exports", "refactor", true},
+	}
+
+	for _, tt := range tests {
+		cc := AngularParser{}.Parse("abc", tt.message)
+		if cc.Type != tt.wantType || cc.Breaking != tt.wantBreak {
+			t.Errorf("Parse(%q) = %+v", tt.message, cc)
+		}
+	}
+}
+
+func TestRegexParser(t *testing.T) {
+	p, err := NewRegexParser(`^\[(?P<type>\w+)\](?:\[(?P<scope>\w+)\])?(?P<breaking>!)? (?P<description>.+)$`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cc := p.Parse("abc", "[FEAT][api]! new endpoint\n\nRefs #12")
+	if cc.Type != "feat" || cc.Scope != "api" || !cc.Breaking || cc.Description != "new endpoint" {
+		t.Errorf("Parse() = %+v", cc)
+	}
+	if len(cc.Footers) != 1 || cc.Footers[0].Value != "12" {
+		t.Errorf("Footers = %+v", cc.Footers)
+	}
+
+	if cc := p.Parse("abc", "feat: not matching"); cc.Type != "" {
+		t.Errorf("expected non-matching message to have empty Type, got %+v", cc)
+	}
+}
+
+func TestNewRegexParserErrors(t *testing.T) {
+	for _, pattern := range []string{"", "(", `^(?P<type>\w+): .+$`} {
+		if _, err := NewRegexParser(pattern); err == nil {
+			t.Errorf("NewRegexParser(%q) expected error", pattern)
+		}
+	}
+}
+
+func TestNewParser(t *testing.T) {
+	for _, convention := range []string{"", "conventional", "Angular", "gitmoji"} {
+		if _, err := NewParser(convention, ""); err != nil {
+			t.Errorf("NewParser(%q) error: %v", convention, err)
+		}
+	}
+	if _, err := NewParser("regex", `^(?P<type>\w+): (?P<description>.+)$`); err != nil {
+		t.Errorf("NewParser(regex) error: %v", err)
+	}
+	if _, err := NewParser("svn", ""); err == nil {
+		t.Error("expected error for unknown convention")
+	}
+}
@@ -36,7 +36,10 @@ type ConventionalCommit struct {
 }
 
 // Footer represents a git trailer / conventional commit footer.
+// Value may span multiple lines. Separator is ":" for "Token: value"
+// footers and "#" for "Token #value" footers such as "Refs #123".
 type Footer struct {
-	Token string
-	Value string
+	Token     string
+	Value     string
+	Separator string
 }
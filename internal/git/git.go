@@ -1,6 +1,8 @@
 package git
 
 import (
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"os/exec"
 	"strings"
@@ -16,7 +18,91 @@ type RawCommit struct {
 	Message string
 }
 
-// Client wraps git operations.
+// Repository is the set of git operations the action performs.
+type Repository interface {
+	IsShallowRepository() (bool, error)
+	HeadCommit() (string, error)
+	FindLatestSemverTag(filter TagFilter) (string, error)
+	ListCommitsSince(rev string) ([]RawCommit, error)
+	LastCommitChanging(path string) (string, error)
+	TagCommit(tag string) (string, error)
+	CommitFiles(message string, paths []string, author Signature) (string, error)
+	CreateTag(tag string) error
+	PushTag(tag string) error
+	PushBranch(branch string) error
+	RebaseOnRemote(branch string, author Signature) error
+}
+
+// Push rejections that callers can handle. PushBranch wraps them with the
+// remote's message.
+var (
+	// ErrNonFastForward means the remote branch has commits that the
+	// local branch lacks.
+	ErrNonFastForward = errors.New("remote branch has new commits")
+	// ErrProtectedBranch means branch protection or a repository rule
+	// rejected the push.
+	ErrProtectedBranch = errors.New("branch is protected")
+)
+
+// Signature identifies the author of a commit.
+type Signature struct {
+	Name  string
+	Email string
+}
+
+// TagFilter selects the tags FindLatestSemverTag considers.
+type TagFilter struct {
+	// Prefix is the tag prefix, e.g. "v".
+	Prefix string
+	// Suffix is a literal tag suffix, e.g. "-lts", stripped before parsing.
+	Suffix string
+	// Legacy lists additional historical tag formats that are also
+	// accepted, e.g. while migrating from "release-*" to "v*" tags.
+	Legacy []semver.TagFormat
+	// IncludeUnreachable also considers tags that are not reachable from
+	// HEAD, such as tags on other branches.
+	IncludeUnreachable bool
+	// Range, if set, restricts tags to a maintenance version line.
+	Range *semver.Range
+	// Match, if set, is an additional predicate a tag's version must satisfy.
+	Match func(semver.Version) bool
+}
+
+// New returns a Repository for workDir using the named backend: "cli"
+// (the default) shells out to the git binary, "go-git" uses a pure-Go
+// implementation. token authenticates go-git pushes over HTTPS; the cli
+// backend relies on the credentials configured by actions/checkout.
+func New(backend, workDir, token string) (Repository, error) {
+	switch strings.ToLower(backend) {
+	case "", "cli":
+		return &Client{WorkDir: workDir}, nil
+	case "go-git":
+		return OpenGoGit(workDir, token)
+	default:
+		return nil, fmt.Errorf("unknown git backend %q", backend)
+	}
+}
+
+// ConfigureToken makes the git binary authenticate to serverURL, e.g.
+// "https://github.com", with token in the repository at workDir. It replaces
+// the credentials actions/checkout persists in the local config, which
+// would otherwise be sent as well.
+func ConfigureToken(workDir, serverURL, token string) error {
+	c := &Client{WorkDir: workDir}
+	key := "http." + strings.TrimSuffix(serverURL, "/") + "/.extraheader"
+	_, _ = c.run("config", "--local", "--unset-all", key)
+
+	// Not c.run: its errors include the arguments, and with them the token.
+	header := "AUTHORIZATION: basic " + base64.StdEncoding.EncodeToString([]byte("x-access-token:"+token))
+	cmd := exec.Command("git", "config", "--local", key, header)
+	cmd.Dir = workDir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git config %s: %w\n%s", key, err, out)
+	}
+	return nil
+}
+
+// Client wraps git operations by running the git binary.
 type Client struct {
 	WorkDir string
 }
@@ -30,43 +116,42 @@ func (c *Client) IsShallowRepository() (bool, error) {
 	return strings.TrimSpace(out) == "true", nil
 }
 
-// FindLatestSemverTag finds the highest semver tag with the given prefix.
-func (c *Client) FindLatestSemverTag(prefix string) (string, error) {
-	out, err := c.run("tag", "--list", prefix+"*", "--sort=-version:refname")
+// HeadCommit returns the full hash of the HEAD commit.
+func (c *Client) HeadCommit() (string, error) {
+	out, err := c.run("rev-parse", "HEAD")
 	if err != nil {
 		return "", err
 	}
+	return strings.TrimSpace(out), nil
+}
 
-	var latest *semver.Version
-	var latestTag string
-
-	for _, line := range strings.Split(out, "\n") {
-		tag := strings.TrimSpace(line)
-		if tag == "" {
-			continue
-		}
-		v, err := semver.Parse(tag)
-		if err != nil {
-			continue // skip non-semver tags
-		}
-		if latest == nil || v.Compare(*latest) > 0 {
-			latest = &v
-			latestTag = tag
-		}
+// FindLatestSemverTag finds the highest semver tag matching filter.
+func (c *Client) FindLatestSemverTag(filter TagFilter) (string, error) {
+	args := []string{"tag", "--list", "--sort=-version:refname"}
+	for _, f := range filter.formats() {
+		args = append(args, f.Pattern())
+	}
+	if !filter.IncludeUnreachable {
+		args = append(args, "--merged", "HEAD")
+	}
+	out, err := c.run(args...)
+	if err != nil {
+		return "", err
 	}
 
-	return latestTag, nil
+	return LatestSemverTag(filter, strings.Split(out, "\n")), nil
 }
 
-// ListCommitsSince lists all commits since the given tag (or all commits if tag is empty).
-func (c *Client) ListCommitsSince(tag string) ([]RawCommit, error) {
+// ListCommitsSince lists all commits since the given tag or commit hash (or
+// all commits if rev is empty).
+func (c *Client) ListCommitsSince(rev string) ([]RawCommit, error) {
 	format := fmt.Sprintf("%%H%%n%%B%%n%s", commitDelimiter)
 
 	var args []string
-	if tag == "" {
+	if rev == "" {
 		args = []string{"log", "--format=" + format, "HEAD"}
 	} else {
-		args = []string{"log", "--format=" + format, tag + "..HEAD"}
+		args = []string{"log", "--format=" + format, rev + "..HEAD"}
 	}
 
 	out, err := c.run(args...)
@@ -77,6 +162,39 @@ func (c *Client) ListCommitsSince(tag string) ([]RawCommit, error) {
 	return parseCommits(out), nil
 }
 
+// LastCommitChanging returns the hash of the most recent commit reachable
+// from HEAD that changed path, or "" if there is none.
+func (c *Client) LastCommitChanging(path string) (string, error) {
+	out, err := c.run("log", "-1", "--format=%H", "HEAD", "--", path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// TagCommit returns the hash of the commit tag points at, or "" if there is
+// no such tag.
+func (c *Client) TagCommit(tag string) (string, error) {
+	out, err := c.run("for-each-ref", "--format=%(if)%(*objectname)%(then)%(*objectname)%(else)%(objectname)%(end)", "refs/tags/"+tag)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// CommitFiles commits the given paths, staged or not, as author and returns
+// the hash of the new commit.
+func (c *Client) CommitFiles(message string, paths []string, author Signature) (string, error) {
+	if _, err := c.run(append([]string{"add", "--"}, paths...)...); err != nil {
+		return "", err
+	}
+	args := []string{"-c", "user.name=" + author.Name, "-c", "user.email=" + author.Email, "commit", "-m", message, "--"}
+	if _, err := c.run(append(args, paths...)...); err != nil {
+		return "", err
+	}
+	return c.HeadCommit()
+}
+
 // CreateTag creates a lightweight tag.
 func (c *Client) CreateTag(tag string) error {
 	_, err := c.run("tag", tag)
@@ -89,6 +207,92 @@ func (c *Client) PushTag(tag string) error {
 	return err
 }
 
+// PushBranch pushes HEAD to branch on the remote.
+func (c *Client) PushBranch(branch string) error {
+	_, err := c.run("push", "origin", "HEAD:refs/heads/"+branch)
+	return classifyPushError(err)
+}
+
+// RebaseOnRemote fetches branch from the remote and rebases the local
+// commits onto it, committing as author.
+func (c *Client) RebaseOnRemote(branch string, author Signature) error {
+	_, err := c.run("-c", "user.name="+author.Name, "-c", "user.email="+author.Email, "pull", "--rebase", "origin", branch)
+	if err != nil {
+		_, _ = c.run("rebase", "--abort")
+	}
+	return err
+}
+
+// classifyPushError wraps a push error with ErrProtectedBranch or
+// ErrNonFastForward when the remote's message identifies the cause.
+func classifyPushError(err error) error {
+	if err == nil {
+		return nil
+	}
+	msg := strings.ToLower(err.Error())
+	for _, s := range []string{"protected branch", "gh006", "gh013", "repository rule violations", "cannot update this protected ref"} {
+		if strings.Contains(msg, s) {
+			return fmt.Errorf("%w: %v", ErrProtectedBranch, err)
+		}
+	}
+	for _, s := range []string{"non-fast-forward", "fetch first"} {
+		if strings.Contains(msg, s) {
+			return fmt.Errorf("%w: %v", ErrNonFastForward, err)
+		}
+	}
+	return err
+}
+
+// LatestSemverTag returns the highest semver tag in tags that has
+// filter.Prefix and filter.Suffix, or a legacy format, and satisfies
+// filter.Range and filter.Match. It returns "" if there is none.
+// Non-semver tags are skipped.
+// Reachability is not checked; callers pass only the tags filter allows.
+func LatestSemverTag(filter TagFilter, tags []string) string {
+	var latest *semver.Version
+	var latestTag string
+
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+		v, err := filter.parse(tag)
+		if err != nil {
+			continue // skip non-semver tags
+		}
+		if filter.Range != nil && !filter.Range.Contains(v) {
+			continue
+		}
+		if filter.Match != nil && !filter.Match(v) {
+			continue
+		}
+		if latest == nil || v.Compare(*latest) > 0 {
+			latest = &v
+			latestTag = tag
+		}
+	}
+
+	return latestTag
+}
+
+// formats returns the current tag format followed by the legacy formats.
+func (f TagFilter) formats() []semver.TagFormat {
+	return append([]semver.TagFormat{{Prefix: f.Prefix, Suffix: f.Suffix}}, f.Legacy...)
+}
+
+// parse parses tag with the first of the filter's formats that accepts it.
+func (f TagFilter) parse(tag string) (semver.Version, error) {
+	var err error
+	for _, format := range f.formats() {
+		var v semver.Version
+		if v, err = format.Parse(tag); err == nil {
+			return v, nil
+		}
+	}
+	return semver.Version{}, err
+}
+
 func (c *Client) run(args ...string) (string, error) {
 	cmd := exec.Command("git", args...)
 	if c.WorkDir != "" {
@@ -0,0 +1,314 @@
+// Package gittest provides an in-memory git repository for testing code
+// that depends on git.Repository without spawning git.
+package gittest
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+
+	"github.com/netwarlan/action-semantic-versioning/internal/git"
+)
+
+// Repo is an in-memory repository of commits, tags and branches that
+// implements git.Repository. The zero value is not usable; call New.
+type Repo struct {
+	// Shallow is returned by IsShallowRepository.
+	Shallow bool
+	// CreateTagErr, PushTagErr and PushBranchErr, if set, are returned by
+	// CreateTag, PushTag and PushBranch instead of performing the operation.
+	CreateTagErr  error
+	PushTagErr    error
+	PushBranchErr error
+	// Pushed records the tags pushed with PushTag, in order.
+	Pushed []string
+	// PushedBranches records the branches pushed with PushBranch, in order.
+	PushedBranches []string
+	// Upstream holds messages of commits on the remote branch that the
+	// local branch lacks. PushBranch is rejected as non-fast-forward until
+	// RebaseOnRemote adds them below the local HEAD commit.
+	Upstream []string
+	// RebaseErr, if set, is returned by RebaseOnRemote.
+	RebaseErr error
+
+	commits  map[string]*commitNode
+	seq      int
+	tags     map[string]string
+	branches map[string]string
+	head     string // current branch name
+}
+
+type commitNode struct {
+	hash    string
+	message string
+	parents []string
+	seq     int
+	files   []string
+	author  git.Signature
+}
+
+var _ git.Repository = (*Repo)(nil)
+
+// New returns an empty repository with "main" checked out.
+func New() *Repo {
+	return &Repo{
+		commits:  map[string]*commitNode{},
+		tags:     map[string]string{},
+		branches: map[string]string{},
+		head:     "main",
+	}
+}
+
+// Commit adds a commit on the current branch and returns its hash.
+func (r *Repo) Commit(message string) string {
+	var parents []string
+	if h := r.branches[r.head]; h != "" {
+		parents = []string{h}
+	}
+	return r.addCommit(message, parents)
+}
+
+// Branch creates a branch at the current HEAD.
+func (r *Repo) Branch(name string) {
+	r.branches[name] = r.branches[r.head]
+}
+
+// Checkout switches HEAD to an existing branch.
+func (r *Repo) Checkout(name string) {
+	if _, ok := r.branches[name]; !ok {
+		panic(fmt.Sprintf("gittest: unknown branch %q", name))
+	}
+	r.head = name
+}
+
+// Merge creates a merge commit of branch into the current branch and
+// returns its hash.
+func (r *Repo) Merge(branch, message string) string {
+	return r.addCommit(message, []string{r.branches[r.head], r.branches[branch]})
+}
+
+// Tag points tag at the current HEAD.
+func (r *Repo) Tag(name string) {
+	r.tags[name] = r.Head()
+}
+
+// Head returns the hash of the current HEAD commit.
+func (r *Repo) Head() string {
+	return r.branches[r.head]
+}
+
+// Tags returns the sorted names of all tags.
+func (r *Repo) Tags() []string {
+	var names []string
+	for name := range r.tags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// TagTarget returns the commit hash a tag points at, or "" if it does not exist.
+func (r *Repo) TagTarget(name string) string {
+	return r.tags[name]
+}
+
+// Message returns the message of a commit.
+func (r *Repo) Message(hash string) string {
+	if n, ok := r.commits[hash]; ok {
+		return n.message
+	}
+	return ""
+}
+
+// Files returns the paths committed with CommitFiles in a commit.
+func (r *Repo) Files(hash string) []string {
+	if n, ok := r.commits[hash]; ok {
+		return n.files
+	}
+	return nil
+}
+
+// Author returns the author passed to CommitFiles for a commit.
+func (r *Repo) Author(hash string) git.Signature {
+	if n, ok := r.commits[hash]; ok {
+		return n.author
+	}
+	return git.Signature{}
+}
+
+// IsShallowRepository implements git.Repository.
+func (r *Repo) IsShallowRepository() (bool, error) {
+	return r.Shallow, nil
+}
+
+// HeadCommit implements git.Repository.
+func (r *Repo) HeadCommit() (string, error) {
+	if r.Head() == "" {
+		return "", fmt.Errorf("no commits")
+	}
+	return r.Head(), nil
+}
+
+// FindLatestSemverTag implements git.Repository.
+func (r *Repo) FindLatestSemverTag(filter git.TagFilter) (string, error) {
+	tags := r.Tags()
+	if !filter.IncludeUnreachable {
+		reachable := r.ancestors(r.Head())
+		var merged []string
+		for _, tag := range tags {
+			if reachable[r.tags[tag]] {
+				merged = append(merged, tag)
+			}
+		}
+		tags = merged
+	}
+	return git.LatestSemverTag(filter, tags), nil
+}
+
+// ListCommitsSince implements git.Repository. rev is a tag or commit hash.
+// Commits are returned newest first, like git log.
+func (r *Repo) ListCommitsSince(rev string) ([]git.RawCommit, error) {
+	hidden := map[string]bool{}
+	if rev != "" {
+		target, ok := r.tags[rev]
+		if !ok {
+			if _, isCommit := r.commits[rev]; !isCommit {
+				return nil, fmt.Errorf("unknown revision %q", rev)
+			}
+			target = rev
+		}
+		hidden = r.ancestors(target)
+	}
+
+	var nodes []*commitNode
+	for hash := range r.ancestors(r.Head()) {
+		if !hidden[hash] {
+			nodes = append(nodes, r.commits[hash])
+		}
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].seq > nodes[j].seq })
+
+	commits := make([]git.RawCommit, 0, len(nodes))
+	for _, n := range nodes {
+		commits = append(commits, git.RawCommit{Hash: n.hash, Message: n.message})
+	}
+	return commits, nil
+}
+
+// LastCommitChanging implements git.Repository. Only paths committed with
+// CommitFiles are known.
+func (r *Repo) LastCommitChanging(path string) (string, error) {
+	var last *commitNode
+	for hash := range r.ancestors(r.Head()) {
+		n := r.commits[hash]
+		if slices.Contains(n.files, path) && (last == nil || n.seq > last.seq) {
+			last = n
+		}
+	}
+	if last == nil {
+		return "", nil
+	}
+	return last.hash, nil
+}
+
+// TagCommit implements git.Repository.
+func (r *Repo) TagCommit(tag string) (string, error) {
+	return r.tags[tag], nil
+}
+
+// CommitFiles implements git.Repository. File contents are not tracked;
+// the paths and author are recorded on the new commit.
+func (r *Repo) CommitFiles(message string, paths []string, author git.Signature) (string, error) {
+	if len(paths) == 0 {
+		return "", fmt.Errorf("nothing to commit")
+	}
+	hash := r.Commit(message)
+	r.commits[hash].files = append([]string(nil), paths...)
+	r.commits[hash].author = author
+	return hash, nil
+}
+
+// CreateTag implements git.Repository.
+func (r *Repo) CreateTag(tag string) error {
+	if r.CreateTagErr != nil {
+		return r.CreateTagErr
+	}
+	if _, ok := r.tags[tag]; ok {
+		return fmt.Errorf("tag %q already exists", tag)
+	}
+	r.Tag(tag)
+	return nil
+}
+
+// PushTag implements git.Repository.
+func (r *Repo) PushTag(tag string) error {
+	if r.PushTagErr != nil {
+		return r.PushTagErr
+	}
+	if _, ok := r.tags[tag]; !ok {
+		return fmt.Errorf("unknown tag %q", tag)
+	}
+	r.Pushed = append(r.Pushed, tag)
+	return nil
+}
+
+// PushBranch implements git.Repository.
+func (r *Repo) PushBranch(branch string) error {
+	if r.PushBranchErr != nil {
+		return r.PushBranchErr
+	}
+	if len(r.Upstream) > 0 {
+		return fmt.Errorf("%w: %s", git.ErrNonFastForward, branch)
+	}
+	r.PushedBranches = append(r.PushedBranches, branch)
+	return nil
+}
+
+// RebaseOnRemote implements git.Repository by replaying the HEAD commit on
+// top of the Upstream commits.
+func (r *Repo) RebaseOnRemote(branch string, author git.Signature) error {
+	if r.RebaseErr != nil {
+		return r.RebaseErr
+	}
+	head := r.commits[r.Head()]
+	r.branches[r.head] = ""
+	if len(head.parents) > 0 {
+		r.branches[r.head] = head.parents[0]
+	}
+	for _, m := range r.Upstream {
+		r.Commit(m)
+	}
+	r.Upstream = nil
+	hash := r.Commit(head.message)
+	r.commits[hash].files = head.files
+	r.commits[hash].author = author
+	return nil
+}
+
+func (r *Repo) addCommit(message string, parents []string) string {
+	r.seq++
+	sum := sha1.Sum([]byte(strconv.Itoa(r.seq) + "\x00" + message))
+	hash := hex.EncodeToString(sum[:])
+	r.commits[hash] = &commitNode{hash: hash, message: message, parents: parents, seq: r.seq}
+	r.branches[r.head] = hash
+	return hash
+}
+
+// ancestors returns the set of commits reachable from hash, including itself.
+func (r *Repo) ancestors(hash string) map[string]bool {
+	seen := map[string]bool{}
+	stack := []string{hash}
+	for len(stack) > 0 {
+		h := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if h == "" || seen[h] {
+			continue
+		}
+		seen[h] = true
+		stack = append(stack, r.commits[h].parents...)
+	}
+	return seen
+}
@@ -0,0 +1,153 @@
+package gittest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/netwarlan/action-semantic-versioning/internal/git"
+)
+
+func TestListCommitsSince(t *testing.T) {
+	r := New()
+	r.Commit("first")
+	r.Tag("v1.0.0")
+	r.Branch("topic")
+	r.Checkout("topic")
+	r.Commit("topic work")
+	r.Checkout("main")
+	r.Commit("main work")
+	r.Merge("topic", "merge topic")
+
+	commits, err := r.ListCommitsSince("v1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, c := range commits {
+		got = append(got, c.Message)
+	}
+	want := []string{"merge topic", "main work", "topic work"}
+	if len(got) != len(want) {
+		t.Fatalf("messages = %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("messages = %q, want %q", got, want)
+			break
+		}
+	}
+
+	all, err := r.ListCommitsSince("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 4 {
+		t.Errorf("expected 4 commits, got %d", len(all))
+	}
+
+	if _, err := r.ListCommitsSince("v9.9.9"); err == nil {
+		t.Error("expected error for unknown tag")
+	}
+}
+
+func TestTagsAndPush(t *testing.T) {
+	r := New()
+	r.Commit("first")
+	r.Tag("v1.0.0")
+	r.Tag("release-1")
+
+	tag, err := r.FindLatestSemverTag(git.TagFilter{Prefix: "v"})
+	if err != nil || tag != "v1.0.0" {
+		t.Errorf("FindLatestSemverTag() = %q, %v", tag, err)
+	}
+
+	r.Commit("second")
+	if err := r.CreateTag("v1.1.0"); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.CreateTag("v1.1.0"); err == nil {
+		t.Error("expected error for duplicate tag")
+	}
+	if r.TagTarget("v1.1.0") != r.Head() {
+		t.Error("tag should point at HEAD")
+	}
+	if err := r.PushTag("v1.1.0"); err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Pushed) != 1 || r.Pushed[0] != "v1.1.0" {
+		t.Errorf("Pushed = %v", r.Pushed)
+	}
+}
+
+func TestCommitFilesAndPushBranch(t *testing.T) {
+	r := New()
+	r.Commit("first")
+
+	author := git.Signature{Name: "bot", Email: "bot@example.com"}
+	hash, err := r.CommitFiles("chore(release): v1.0.0", []string{"package.json"}, author)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hash != r.Head() || r.Message(hash) != "chore(release): v1.0.0" || r.Author(hash) != author {
+		t.Errorf("commit %s not recorded at HEAD", hash)
+	}
+	if files := r.Files(hash); len(files) != 1 || files[0] != "package.json" {
+		t.Errorf("Files() = %v", files)
+	}
+	if _, err := r.CommitFiles("empty", nil, author); err == nil {
+		t.Error("expected error for empty commit")
+	}
+
+	if err := r.PushBranch("main"); err != nil {
+		t.Fatal(err)
+	}
+	if len(r.PushedBranches) != 1 || r.PushedBranches[0] != "main" {
+		t.Errorf("PushedBranches = %v", r.PushedBranches)
+	}
+}
+
+func TestLastCommitChanging(t *testing.T) {
+	r := New()
+	r.Commit("first")
+	author := git.Signature{Name: "bot", Email: "bot@example.com"}
+	release, _ := r.CommitFiles("chore(release): 1.0.0", []string{"VERSION"}, author)
+	r.Commit("feat: a")
+
+	if got, err := r.LastCommitChanging("VERSION"); err != nil || got != release {
+		t.Errorf("LastCommitChanging() = %q, %v; want %s", got, err, release)
+	}
+	if got, _ := r.LastCommitChanging("package.json"); got != "" {
+		t.Errorf("LastCommitChanging(package.json) = %q, want empty", got)
+	}
+
+	commits, err := r.ListCommitsSince(release)
+	if err != nil || len(commits) != 1 || commits[0].Message != "feat: a" {
+		t.Errorf("ListCommitsSince(%s) = %+v, %v", release, commits, err)
+	}
+}
+
+func TestRebaseOnRemote(t *testing.T) {
+	r := New()
+	base := r.Commit("first")
+	author := git.Signature{Name: "bot", Email: "bot@example.com"}
+	r.CommitFiles("chore(release): 1.0.0", []string{"VERSION"}, author)
+	r.Upstream = []string{"fix: concurrent"}
+
+	if err := r.PushBranch("main"); !errors.Is(err, git.ErrNonFastForward) {
+		t.Fatalf("PushBranch() = %v, want ErrNonFastForward", err)
+	}
+	if err := r.RebaseOnRemote("main", author); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.PushBranch("main"); err != nil {
+		t.Fatal(err)
+	}
+
+	commits, _ := r.ListCommitsSince(base)
+	if len(commits) != 2 || commits[0].Message != "chore(release): 1.0.0" || commits[1].Message != "fix: concurrent" {
+		t.Errorf("commits after rebase = %+v", commits)
+	}
+	if files := r.Files(r.Head()); len(files) != 1 || files[0] != "VERSION" {
+		t.Errorf("rebased commit files = %v", files)
+	}
+}
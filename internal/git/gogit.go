@@ -0,0 +1,500 @@
+package git
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-billy/v5/util"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+)
+
+// GoGitClient implements Repository in pure Go, without a git binary.
+type GoGitClient struct {
+	repo  *gogit.Repository
+	token string
+}
+
+// OpenGoGit opens the repository containing workDir (the current directory
+// if empty). token, if set, is used to authenticate pushes over HTTPS.
+func OpenGoGit(workDir, token string) (*GoGitClient, error) {
+	if workDir == "" {
+		workDir = "."
+	}
+	repo, err := gogit.PlainOpenWithOptions(workDir, &gogit.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return nil, fmt.Errorf("open repository %s: %w", workDir, err)
+	}
+	return &GoGitClient{repo: repo, token: token}, nil
+}
+
+// IsShallowRepository checks if the repository is a shallow clone.
+func (c *GoGitClient) IsShallowRepository() (bool, error) {
+	shallow, err := c.repo.Storer.Shallow()
+	if err != nil {
+		return false, fmt.Errorf("read shallow commits: %w", err)
+	}
+	return len(shallow) > 0, nil
+}
+
+// HeadCommit returns the full hash of the HEAD commit.
+func (c *GoGitClient) HeadCommit() (string, error) {
+	head, err := c.repo.Head()
+	if err != nil {
+		return "", fmt.Errorf("resolve HEAD: %w", err)
+	}
+	return head.Hash().String(), nil
+}
+
+// FindLatestSemverTag finds the highest semver tag matching filter.
+func (c *GoGitClient) FindLatestSemverTag(filter TagFilter) (string, error) {
+	var reachable map[plumbing.Hash]bool
+	if !filter.IncludeUnreachable {
+		head, err := c.headCommit()
+		if err != nil {
+			return "", err
+		}
+		reachable = map[plumbing.Hash]bool{}
+		if err := walkAncestors(head, func(cm *object.Commit) { reachable[cm.Hash] = true }); err != nil {
+			return "", err
+		}
+	}
+
+	tags, err := c.repo.Tags()
+	if err != nil {
+		return "", fmt.Errorf("list tags: %w", err)
+	}
+
+	var names []string
+	err = tags.ForEach(func(ref *plumbing.Reference) error {
+		name := ref.Name().Short()
+		if _, err := filter.parse(name); err != nil {
+			return nil
+		}
+		if reachable != nil {
+			cm, err := c.tagCommit(name)
+			if err != nil || !reachable[cm.Hash] {
+				return nil // tags on other branches or non-commit objects are skipped
+			}
+		}
+		names = append(names, name)
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("list tags: %w", err)
+	}
+
+	return LatestSemverTag(filter, names), nil
+}
+
+// ListCommitsSince lists all commits since the given tag or commit hash (or
+// all commits if rev is empty), most recent first like git log.
+func (c *GoGitClient) ListCommitsSince(rev string) ([]RawCommit, error) {
+	head, err := c.headCommit()
+	if err != nil {
+		return nil, err
+	}
+
+	// Like "git log rev..HEAD", walk both histories together, newest first,
+	// hiding everything reachable from rev. The walk stops once only hidden
+	// commits older than the listed ones are left, rather than visiting all
+	// of either history.
+	w := &commitWalk{entries: map[plumbing.Hash]*walkEntry{}}
+	w.add(head, false)
+	if rev != "" {
+		since, err := c.tagCommit(rev)
+		if err != nil && plumbing.IsHash(rev) {
+			since, err = c.repo.CommitObject(plumbing.NewHash(rev))
+		}
+		if err != nil {
+			return nil, err
+		}
+		w.add(since, true)
+	}
+
+	var listed []*walkEntry
+	var oldest time.Time
+	for w.queue.Len() > 0 && (w.visible > 0 || (len(listed) > 0 && !w.queue[0].commit.Committer.When.Before(oldest))) {
+		e := heap.Pop(&w.queue).(*walkEntry)
+		e.queued = false
+		if !e.hidden {
+			w.visible--
+			listed = append(listed, e)
+			oldest = e.commit.Committer.When
+		}
+		err := e.commit.Parents().ForEach(func(parent *object.Commit) error {
+			w.add(parent, e.hidden)
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("log: %w", err)
+		}
+	}
+
+	// Commits with equal timestamps can be listed before they are hidden.
+	var commits []RawCommit
+	for _, e := range listed {
+		if !e.hidden {
+			commits = append(commits, RawCommit{Hash: e.commit.Hash.String(), Message: strings.TrimSpace(e.commit.Message)})
+		}
+	}
+	return commits, nil
+}
+
+// commitWalk is a queue of commits ordered by committer time, newest first,
+// that tracks how many queued commits are not hidden.
+type commitWalk struct {
+	queue   commitQueue
+	entries map[plumbing.Hash]*walkEntry
+	visible int
+}
+
+type walkEntry struct {
+	commit *object.Commit
+	hidden bool
+	queued bool
+}
+
+// add queues cm unless it was seen before. A commit seen before that turns
+// out to be hidden is hidden from the output, and queued again if it was
+// already walked so that its parents are hidden as well.
+func (w *commitWalk) add(cm *object.Commit, hidden bool) {
+	if e, ok := w.entries[cm.Hash]; ok {
+		if !hidden || e.hidden {
+			return
+		}
+		e.hidden = true
+		if e.queued {
+			w.visible--
+		} else {
+			e.queued = true
+			heap.Push(&w.queue, e)
+		}
+		return
+	}
+	e := &walkEntry{commit: cm, hidden: hidden, queued: true}
+	w.entries[cm.Hash] = e
+	heap.Push(&w.queue, e)
+	if !hidden {
+		w.visible++
+	}
+}
+
+// commitQueue implements heap.Interface.
+type commitQueue []*walkEntry
+
+func (q commitQueue) Len() int { return len(q) }
+func (q commitQueue) Less(i, j int) bool {
+	return q[i].commit.Committer.When.After(q[j].commit.Committer.When)
+}
+func (q commitQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+func (q *commitQueue) Push(x any)   { *q = append(*q, x.(*walkEntry)) }
+func (q *commitQueue) Pop() any {
+	old := *q
+	e := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return e
+}
+
+// LastCommitChanging returns the hash of the most recent commit reachable
+// from HEAD that changed path, or "" if there is none. Like git log, a
+// merge that took path unchanged from one of its parents is not reported;
+// the search continues down that parent.
+func (c *GoGitClient) LastCommitChanging(path string) (string, error) {
+	cm, err := c.headCommit()
+	if err != nil {
+		return "", err
+	}
+	for {
+		file, err := fileHash(cm, path)
+		if err != nil {
+			return "", err
+		}
+		var next *object.Commit
+		for i := 0; i < cm.NumParents() && next == nil; i++ {
+			parent, err := cm.Parent(i)
+			if err != nil {
+				return "", fmt.Errorf("log %s: %w", path, err)
+			}
+			parentFile, err := fileHash(parent, path)
+			if err != nil {
+				return "", err
+			}
+			if parentFile == file {
+				next = parent
+			}
+		}
+		switch {
+		case next != nil:
+			cm = next
+		case file.IsZero():
+			// A root commit without the file.
+			return "", nil
+		default:
+			return cm.Hash.String(), nil
+		}
+	}
+}
+
+// fileHash returns the blob hash of path in cm, or the zero hash if cm does
+// not have the file.
+func fileHash(cm *object.Commit, path string) (plumbing.Hash, error) {
+	tree, err := cm.Tree()
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("read tree of %s: %w", cm.Hash, err)
+	}
+	entry, err := tree.FindEntry(path)
+	if errors.Is(err, object.ErrEntryNotFound) || errors.Is(err, object.ErrDirectoryNotFound) {
+		return plumbing.ZeroHash, nil
+	}
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("read %s in %s: %w", path, cm.Hash, err)
+	}
+	return entry.Hash, nil
+}
+
+// CommitFiles commits the given paths as author and returns the hash of the
+// new commit. go-git commits the whole index, so other staged changes are
+// refused rather than committed with them.
+func (c *GoGitClient) CommitFiles(message string, paths []string, author Signature) (string, error) {
+	wt, err := c.repo.Worktree()
+	if err != nil {
+		return "", fmt.Errorf("open worktree: %w", err)
+	}
+	status, err := wt.Status()
+	if err != nil {
+		return "", fmt.Errorf("worktree status: %w", err)
+	}
+	for path, s := range status {
+		staged := s.Staging != gogit.Unmodified && s.Staging != gogit.Untracked
+		if staged && !slices.Contains(paths, path) {
+			return "", fmt.Errorf("commit: %s has staged changes that are not part of the commit", path)
+		}
+	}
+	for _, p := range paths {
+		if _, err := wt.Add(p); err != nil {
+			return "", fmt.Errorf("add %s: %w", p, err)
+		}
+	}
+	hash, err := wt.Commit(message, &gogit.CommitOptions{
+		Author: &object.Signature{Name: author.Name, Email: author.Email, When: time.Now()},
+	})
+	if err != nil {
+		return "", fmt.Errorf("commit: %w", err)
+	}
+	return hash.String(), nil
+}
+
+// TagCommit returns the hash of the commit tag points at, or "" if there is
+// no such tag.
+func (c *GoGitClient) TagCommit(tag string) (string, error) {
+	if _, err := c.repo.Tag(tag); errors.Is(err, gogit.ErrTagNotFound) {
+		return "", nil
+	}
+	cm, err := c.tagCommit(tag)
+	if err != nil {
+		return "", err
+	}
+	return cm.Hash.String(), nil
+}
+
+// CreateTag creates a lightweight tag at HEAD.
+func (c *GoGitClient) CreateTag(tag string) error {
+	head, err := c.repo.Head()
+	if err != nil {
+		return fmt.Errorf("resolve HEAD: %w", err)
+	}
+	if _, err := c.repo.CreateTag(tag, head.Hash(), nil); err != nil {
+		return fmt.Errorf("create tag %s: %w", tag, err)
+	}
+	return nil
+}
+
+// PushTag pushes a tag to the origin remote.
+func (c *GoGitClient) PushTag(tag string) error {
+	if err := c.push(config.RefSpec(fmt.Sprintf("refs/tags/%s:refs/tags/%s", tag, tag))); err != nil {
+		return fmt.Errorf("push tag %s: %w", tag, err)
+	}
+	return nil
+}
+
+// PushBranch pushes HEAD to branch on the origin remote.
+func (c *GoGitClient) PushBranch(branch string) error {
+	head, err := c.repo.Head()
+	if err != nil {
+		return fmt.Errorf("resolve HEAD: %w", err)
+	}
+	if err := c.push(config.RefSpec(fmt.Sprintf("%s:refs/heads/%s", head.Hash(), branch))); err != nil {
+		return classifyPushError(fmt.Errorf("push branch %s: %w", branch, err))
+	}
+	return nil
+}
+
+// RebaseOnRemote fetches branch from origin and replays the HEAD commit onto
+// it, committing as author. go-git cannot rebase, so only a single commit
+// whose files were not also changed on the remote can be replayed, which is
+// the shape of a release commit.
+func (c *GoGitClient) RebaseOnRemote(branch string, author Signature) error {
+	auth, err := c.auth()
+	if err != nil {
+		return err
+	}
+	remoteRef := plumbing.NewRemoteReferenceName("origin", branch)
+	err = c.repo.Fetch(&gogit.FetchOptions{
+		RemoteName: "origin",
+		RefSpecs:   []config.RefSpec{config.RefSpec(fmt.Sprintf("+refs/heads/%s:%s", branch, remoteRef))},
+		Auth:       auth,
+	})
+	if err != nil && !errors.Is(err, gogit.NoErrAlreadyUpToDate) {
+		return fmt.Errorf("fetch %s: %w", branch, err)
+	}
+	ref, err := c.repo.Reference(remoteRef, true)
+	if err != nil {
+		return fmt.Errorf("resolve %s: %w", remoteRef, err)
+	}
+	upstream, err := c.repo.CommitObject(ref.Hash())
+	if err != nil {
+		return fmt.Errorf("resolve %s: %w", remoteRef, err)
+	}
+
+	head, err := c.headCommit()
+	if err != nil {
+		return err
+	}
+	if head.NumParents() != 1 {
+		return fmt.Errorf("rebase %s: HEAD is not a single commit on top of its parent", branch)
+	}
+	parent, err := head.Parent(0)
+	if err != nil {
+		return fmt.Errorf("rebase %s: %w", branch, err)
+	}
+	if ok, err := parent.IsAncestor(upstream); err != nil || !ok {
+		return fmt.Errorf("rebase %s: HEAD has more than one commit not on the remote", branch)
+	}
+
+	// Collect the files HEAD changed, refusing to replay over remote changes.
+	changes, err := parent.Patch(head)
+	if err != nil {
+		return fmt.Errorf("rebase %s: %w", branch, err)
+	}
+	contents := map[string]string{}
+	for _, fp := range changes.FilePatches() {
+		_, to := fp.Files()
+		if to == nil {
+			return fmt.Errorf("rebase %s: HEAD deletes or renames files", branch)
+		}
+		path := to.Path()
+		before, _ := parent.File(path)
+		after, _ := upstream.File(path)
+		if (before == nil) != (after == nil) || (before != nil && before.Hash != after.Hash) {
+			return fmt.Errorf("rebase %s: %s was also changed on the remote", branch, path)
+		}
+		f, err := head.File(path)
+		if err != nil {
+			return fmt.Errorf("rebase %s: %w", branch, err)
+		}
+		if contents[path], err = f.Contents(); err != nil {
+			return fmt.Errorf("rebase %s: %w", branch, err)
+		}
+	}
+
+	wt, err := c.repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("open worktree: %w", err)
+	}
+	if err := wt.Reset(&gogit.ResetOptions{Commit: upstream.Hash, Mode: gogit.HardReset}); err != nil {
+		return fmt.Errorf("rebase %s: %w", branch, err)
+	}
+	var paths []string
+	for path, content := range contents {
+		if err := util.WriteFile(wt.Filesystem, path, []byte(content), 0644); err != nil {
+			return fmt.Errorf("rebase %s: %w", branch, err)
+		}
+		paths = append(paths, path)
+	}
+	if _, err := c.CommitFiles(head.Message, paths, author); err != nil {
+		return fmt.Errorf("rebase %s: %w", branch, err)
+	}
+	return nil
+}
+
+// push pushes ref to origin; an up-to-date remote is not an error.
+func (c *GoGitClient) push(ref config.RefSpec) error {
+	auth, err := c.auth()
+	if err != nil {
+		return err
+	}
+	err = c.repo.Push(&gogit.PushOptions{
+		RemoteName: "origin",
+		RefSpecs:   []config.RefSpec{ref},
+		Auth:       auth,
+	})
+	if err != nil && !errors.Is(err, gogit.NoErrAlreadyUpToDate) {
+		return err
+	}
+	return nil
+}
+
+// auth returns token credentials for HTTP(S) remotes. Other transports
+// (file, ssh) use their own authentication.
+func (c *GoGitClient) auth() (transport.AuthMethod, error) {
+	if c.token == "" {
+		return nil, nil
+	}
+	remote, err := c.repo.Remote("origin")
+	if err != nil {
+		return nil, fmt.Errorf("remote origin: %w", err)
+	}
+	urls := remote.Config().URLs
+	if len(urls) == 0 || !strings.HasPrefix(urls[0], "http") {
+		return nil, nil
+	}
+	return &http.BasicAuth{Username: "x-access-token", Password: c.token}, nil
+}
+
+// headCommit returns the commit HEAD points at.
+func (c *GoGitClient) headCommit() (*object.Commit, error) {
+	head, err := c.repo.Head()
+	if err != nil {
+		return nil, fmt.Errorf("resolve HEAD: %w", err)
+	}
+	cm, err := c.repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("resolve HEAD: %w", err)
+	}
+	return cm, nil
+}
+
+// tagCommit resolves a tag name to its commit, peeling annotated tags.
+func (c *GoGitClient) tagCommit(tag string) (*object.Commit, error) {
+	ref, err := c.repo.Tag(tag)
+	if err != nil {
+		return nil, fmt.Errorf("resolve tag %s: %w", tag, err)
+	}
+	if to, err := c.repo.TagObject(ref.Hash()); err == nil {
+		return to.Commit()
+	}
+	cm, err := c.repo.CommitObject(ref.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("resolve tag %s: %w", tag, err)
+	}
+	return cm, nil
+}
+
+// walkAncestors calls fn for from and every commit reachable from it.
+func walkAncestors(from *object.Commit, fn func(*object.Commit)) error {
+	iter := object.NewCommitPreorderIter(from, nil, nil)
+	defer iter.Close()
+	return iter.ForEach(func(cm *object.Commit) error {
+		fn(cm)
+		return nil
+	})
+}
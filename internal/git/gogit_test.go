@@ -0,0 +1,364 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func openGoGit(t *testing.T, dir string) *GoGitClient {
+	t.Helper()
+	c, err := OpenGoGit(dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestGoGitFindLatestSemverTag(t *testing.T) {
+	dir := setupTestRepo(t)
+	makeCommit(t, dir, "first")
+	createTag(t, dir, "v1.0.0")
+	makeCommit(t, dir, "second")
+	createTag(t, dir, "v1.10.0")
+	makeCommit(t, dir, "third")
+	createTag(t, dir, "v1.9.0")
+	createTag(t, dir, "release-1")
+
+	tag, err := openGoGit(t, dir).FindLatestSemverTag(TagFilter{Prefix: "v"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tag != "v1.10.0" {
+		t.Errorf("expected v1.10.0, got %q", tag)
+	}
+}
+
+func TestGoGitListCommitsSince(t *testing.T) {
+	dir := setupTestRepo(t)
+	makeCommit(t, dir, "feat: first feature")
+	runGit(t, dir, "tag", "-a", "v1.0.0", "-m", "annotated")
+	makeCommit(t, dir, "fix: a bug")
+	makeCommit(t, dir, "feat: second feature\n\nBREAKING CHANGE: removed thing")
+
+	c := openGoGit(t, dir)
+	commits, err := c.ListCommitsSince("v1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(commits) != 2 {
+		t.Fatalf("expected 2 commits, got %d", len(commits))
+	}
+	if commits[0].Message != "feat: second feature\n\nBREAKING CHANGE: removed thing" {
+		t.Errorf("commit[0].Message = %q", commits[0].Message)
+	}
+	if commits[1].Message != "fix: a bug" {
+		t.Errorf("commit[1].Message = %q", commits[1].Message)
+	}
+	if want := runGit(t, dir, "rev-parse", "HEAD"); commits[0].Hash != want {
+		t.Errorf("commit[0].Hash = %q, want %q", commits[0].Hash, want)
+	}
+
+	all, err := c.ListCommitsSince("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 3 {
+		t.Errorf("expected 3 commits, got %d", len(all))
+	}
+}
+
+func TestGoGitListCommitsSinceMerge(t *testing.T) {
+	dir := setupTestRepo(t)
+	makeCommit(t, dir, "feat: base")
+	createTag(t, dir, "v1.0.0")
+	base := runGit(t, dir, "rev-parse", "--abbrev-ref", "HEAD")
+	runGit(t, dir, "checkout", "-b", "topic")
+	makeCommit(t, dir, "fix: on topic")
+	runGit(t, dir, "checkout", base)
+	runGit(t, dir, "merge", "--no-ff", "-m", "Merge topic", "topic")
+
+	want, err := (&Client{WorkDir: dir}).ListCommitsSince("v1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := openGoGit(t, dir).ListCommitsSince("v1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("go-git listed %d commits, cli listed %d", len(got), len(want))
+	}
+}
+
+func TestGoGitListCommitsSinceEqualTimes(t *testing.T) {
+	// Commits made within the same second leave the walk no order to go by.
+	t.Setenv("GIT_AUTHOR_DATE", "2026-01-01T00:00:00Z")
+	t.Setenv("GIT_COMMITTER_DATE", "2026-01-01T00:00:00Z")
+
+	dir := setupTestRepo(t)
+	commitFile(t, dir, "f1.txt", "feat: base")
+	base := runGit(t, dir, "rev-parse", "--abbrev-ref", "HEAD")
+	runGit(t, dir, "checkout", "-b", "topic")
+	commitFile(t, dir, "f2.txt", "fix: on topic")
+	commitFile(t, dir, "f3.txt", "fix: more on topic")
+	runGit(t, dir, "checkout", base)
+	commitFile(t, dir, "f4.txt", "feat: on base")
+	runGit(t, dir, "merge", "--no-ff", "-m", "Merge topic", "topic")
+	createTag(t, dir, "v1.0.0")
+	runGit(t, dir, "checkout", "-b", "next", "topic")
+	commitFile(t, dir, "f5.txt", "fix: after topic")
+	runGit(t, dir, "merge", "--no-ff", "-m", "Merge base", base)
+	commitFile(t, dir, "f6.txt", "feat: new")
+
+	c := openGoGit(t, dir)
+	// The hidden history reaches the shared commits later than HEAD's.
+	runGit(t, dir, "checkout", "-b", "long", "topic~1")
+	for i := range 4 {
+		commitFile(t, dir, fmt.Sprintf("long%d.txt", i), "fix: long branch")
+	}
+	createTag(t, dir, "v2.0.0")
+	runGit(t, dir, "checkout", "-b", "ahead", "next")
+	for i := range 4 {
+		commitFile(t, dir, fmt.Sprintf("ahead%d.txt", i), "fix: ahead of HEAD")
+	}
+	createTag(t, dir, "v3.0.0")
+	runGit(t, dir, "checkout", "next")
+
+	topic := runGit(t, dir, "rev-parse", "topic")
+	head := runGit(t, dir, "rev-parse", "HEAD")
+	for _, rev := range []string{"v1.0.0", "v2.0.0", "v3.0.0", topic, head, ""} {
+		want, err := (&Client{WorkDir: dir}).ListCommitsSince(rev)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := c.ListCommitsSince(rev)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !sameCommits(got, want) {
+			t.Errorf("ListCommitsSince(%q): go-git listed %v, cli listed %v", rev, got, want)
+		}
+	}
+}
+
+// commitFile commits a new file, so that branches merge without conflicts.
+func commitFile(t *testing.T, dir, name, message string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(message+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, dir, "add", name)
+	runGit(t, dir, "commit", "-m", message)
+}
+
+// sameCommits reports whether a and b list the same commits in any order.
+func sameCommits(a, b []RawCommit) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	seen := map[string]bool{}
+	for _, c := range a {
+		seen[c.Hash] = true
+	}
+	for _, c := range b {
+		if !seen[c.Hash] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestGoGitCreateAndPushTag(t *testing.T) {
+	remote := t.TempDir()
+	runGit(t, remote, "init", "--bare")
+
+	dir := setupTestRepo(t)
+	makeCommit(t, dir, "initial")
+	runGit(t, dir, "remote", "add", "origin", remote)
+
+	c := openGoGit(t, dir)
+	if err := c.CreateTag("v1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	if got := runGit(t, dir, "tag", "--list", "v1.0.0"); got != "v1.0.0" {
+		t.Errorf("tag list = %q, want v1.0.0", got)
+	}
+
+	if err := c.PushTag("v1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	if got := runGit(t, remote, "tag", "--list"); got != "v1.0.0" {
+		t.Errorf("remote tags = %q, want v1.0.0", got)
+	}
+}
+
+// setupRemoteRace returns a clone of a bare remote on which the go-git
+// client committed a release that changes VERSION, while another clone
+// pushed commits writing files to main in the meantime.
+func setupRemoteRace(t *testing.T, local, remoteFiles []string) (dir, remote string, c *GoGitClient) {
+	t.Helper()
+	remote = t.TempDir()
+	runGit(t, remote, "init", "--bare")
+
+	dir = setupTestRepo(t)
+	makeCommit(t, dir, "initial")
+	runGit(t, dir, "remote", "add", "origin", remote)
+	runGit(t, dir, "push", "origin", "HEAD:refs/heads/main")
+
+	other := filepath.Join(t.TempDir(), "other")
+	runGit(t, remote, "clone", "--branch", "main", remote, other)
+	runGit(t, other, "config", "user.email", "other@test.com")
+	runGit(t, other, "config", "user.name", "Other")
+	for _, name := range remoteFiles {
+		if err := os.WriteFile(filepath.Join(other, name), []byte("remote\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		runGit(t, other, "add", name)
+		runGit(t, other, "commit", "-m", "fix: change "+name)
+	}
+	runGit(t, other, "push", "origin", "HEAD:refs/heads/main")
+
+	c = openGoGit(t, dir)
+	for _, msg := range local {
+		if err := os.WriteFile(filepath.Join(dir, "VERSION"), []byte(msg+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.CommitFiles(msg, []string{"VERSION"}, Signature{Name: "Release Bot", Email: "bot@example.com"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir, remote, c
+}
+
+func TestGoGitRebaseOnRemote(t *testing.T) {
+	dir, remote, c := setupRemoteRace(t, []string{"chore(release): 1.0.0"}, []string{"a.txt", "b.txt"})
+
+	if err := c.PushBranch("main"); !errors.Is(err, ErrNonFastForward) {
+		t.Fatalf("PushBranch() = %v, want ErrNonFastForward", err)
+	}
+	if err := c.RebaseOnRemote("main", Signature{Name: "Release Bot", Email: "bot@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.PushBranch("main"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := runGit(t, remote, "log", "--format=%s", "main"); got != "chore(release): 1.0.0\nfix: change b.txt\nfix: change a.txt\ninitial" {
+		t.Errorf("remote log =\n%s", got)
+	}
+	if got := runGit(t, remote, "show", "--name-only", "--format=%an", "main"); got != "Release Bot\n\nVERSION" {
+		t.Errorf("release commit = %q", got)
+	}
+	if got := runGit(t, dir, "status", "--porcelain"); got != "" {
+		t.Errorf("worktree not clean after rebase:\n%s", got)
+	}
+	for name, want := range map[string]string{"VERSION": "chore(release): 1.0.0\n", "b.txt": "remote\n"} {
+		if got, _ := os.ReadFile(filepath.Join(dir, name)); string(got) != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestGoGitRebaseOnRemoteRefuses(t *testing.T) {
+	tests := []struct {
+		name        string
+		local       []string
+		remoteFiles []string
+		want        string
+	}{
+		{"file also changed on remote", []string{"chore(release): 1.0.0"}, []string{"VERSION"}, "VERSION was also changed on the remote"},
+		{"several local commits", []string{"chore: one", "chore(release): 1.0.0"}, []string{"a.txt"}, "more than one commit"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, _, c := setupRemoteRace(t, tt.local, tt.remoteFiles)
+			head := runGit(t, dir, "rev-parse", "HEAD")
+
+			err := c.RebaseOnRemote("main", Signature{Name: "Release Bot", Email: "bot@example.com"})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("RebaseOnRemote() = %v, want %q", err, tt.want)
+			}
+			if got := runGit(t, dir, "rev-parse", "HEAD"); got != head {
+				t.Errorf("HEAD moved to %s after a refused rebase", got)
+			}
+		})
+	}
+}
+
+func TestGoGitLastCommitChangingMerge(t *testing.T) {
+	dir := setupTestRepo(t)
+	makeCommit(t, dir, "initial")
+	base := runGit(t, dir, "rev-parse", "--abbrev-ref", "HEAD")
+	runGit(t, dir, "checkout", "-b", "topic")
+	if err := os.WriteFile(filepath.Join(dir, "VERSION"), []byte("1.0.0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, dir, "add", "VERSION")
+	runGit(t, dir, "commit", "-m", "chore(release): 1.0.0")
+	release := runGit(t, dir, "rev-parse", "HEAD")
+	runGit(t, dir, "checkout", base)
+	makeCommit(t, dir, "feat: on base")
+	runGit(t, dir, "merge", "--no-ff", "-m", "Merge topic", "topic")
+	makeCommit(t, dir, "fix: after merge")
+
+	want, err := (&Client{WorkDir: dir}).LastCommitChanging("VERSION")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := openGoGit(t, dir).LastCommitChanging("VERSION")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want || got != release {
+		t.Errorf("go-git LastCommitChanging() = %s, cli = %s, want %s", got, want, release)
+	}
+}
+
+func TestGoGitIsShallowRepository(t *testing.T) {
+	dir := setupTestRepo(t)
+	makeCommit(t, dir, "initial")
+
+	shallow, err := openGoGit(t, dir).IsShallowRepository()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shallow {
+		t.Error("expected non-shallow repo")
+	}
+}
+
+func TestNew(t *testing.T) {
+	dir := setupTestRepo(t)
+	makeCommit(t, dir, "initial")
+
+	if r, err := New("", dir, ""); err != nil {
+		t.Fatal(err)
+	} else if _, ok := r.(*Client); !ok {
+		t.Errorf("default backend = %T, want *Client", r)
+	}
+	if r, err := New("go-git", dir, ""); err != nil {
+		t.Fatal(err)
+	} else if _, ok := r.(*GoGitClient); !ok {
+		t.Errorf("go-git backend = %T, want *GoGitClient", r)
+	}
+	if _, err := New("svn", dir, ""); err == nil {
+		t.Error("expected error for unknown backend")
+	}
+}
@@ -0,0 +1,127 @@
+package github
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// AppClient authenticates as a GitHub App installation. Unlike the
+// workflow's GITHUB_TOKEN, pushes and releases made with an installation
+// token trigger other workflows.
+type AppClient struct {
+	AppID   string
+	Key     *rsa.PrivateKey
+	Repo    string          // "owner/repo" from GITHUB_REPOSITORY
+	APIURL  string          // from GITHUB_API_URL, defaults to "https://api.github.com"
+	HTTP    *Client         // sends requests; defaults to NewClient(DefaultTimeout)
+	Context context.Context // cancels requests; defaults to context.Background()
+
+	now func() time.Time // defaults to time.Now
+}
+
+// NewAppClient creates a client for the app with the given ID and
+// PEM-encoded private key, for the repository in the environment.
+func NewAppClient(appID, privateKey string) (*AppClient, error) {
+	key, err := ParsePrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	apiURL := os.Getenv("GITHUB_API_URL")
+	if apiURL == "" {
+		apiURL = "https://api.github.com"
+	}
+	return &AppClient{
+		AppID:  appID,
+		Key:    key,
+		Repo:   os.Getenv("GITHUB_REPOSITORY"),
+		APIURL: apiURL,
+	}, nil
+}
+
+// ParsePrivateKey parses an app private key in PKCS #1 or PKCS #8 PEM form,
+// as downloaded from the app settings.
+func ParsePrivateKey(s string) (*rsa.PrivateKey, error) {
+	// Secrets pasted into single-line fields often carry escaped newlines.
+	s = strings.ReplaceAll(strings.TrimSpace(s), `\n`, "\n")
+	block, _ := pem.Decode([]byte(s))
+	if block == nil {
+		return nil, errors.New("private key is not PEM-encoded")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse private key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("private key is not an RSA key")
+	}
+	return key, nil
+}
+
+// JWT returns a token that authenticates as the app itself. It is valid for
+// nine minutes and backdated by one to allow for clock drift, within the ten
+// minutes GitHub accepts.
+func (c *AppClient) JWT() (string, error) {
+	now := time.Now()
+	if c.now != nil {
+		now = c.now()
+	}
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]any{
+		"iat": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(9 * time.Minute).Unix(),
+		"iss": c.AppID,
+	})
+	enc := base64.RawURLEncoding
+	unsigned := enc.EncodeToString(header) + "." + enc.EncodeToString(claims)
+
+	digest := sha256.Sum256([]byte(unsigned))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, c.Key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("sign app token: %w", err)
+	}
+	return unsigned + "." + enc.EncodeToString(sig), nil
+}
+
+// InstallationToken looks up the app's installation on the repository and
+// returns a new access token for it, valid for one hour.
+func (c *AppClient) InstallationToken() (string, error) {
+	jwt, err := c.JWT()
+	if err != nil {
+		return "", err
+	}
+	api := &ReleaseClient{Token: jwt, Repo: c.Repo, APIURL: c.APIURL, HTTP: c.HTTP, Context: c.Context}
+
+	var installation struct {
+		ID int64 `json:"id"`
+	}
+	if err := api.do("GET", fmt.Sprintf("%s/repos/%s/installation", c.APIURL, c.Repo), nil, &installation); err != nil {
+		return "", fmt.Errorf("find app installation on %s: %w", c.Repo, err)
+	}
+
+	var token struct {
+		Token string `json:"token"`
+	}
+	if err := api.do("POST", fmt.Sprintf("%s/app/installations/%d/access_tokens", c.APIURL, installation.ID), strings.NewReader("{}"), &token); err != nil {
+		return "", fmt.Errorf("create installation token: %w", err)
+	}
+	if token.Token == "" {
+		return "", errors.New("create installation token: empty token in response")
+	}
+	return token.Token, nil
+}
@@ -0,0 +1,132 @@
+package github
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestParsePrivateKey(t *testing.T) {
+	key := testKey(t)
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkcs1PEM := string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}))
+	pkcs8PEM := string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8}))
+
+	for name, s := range map[string]string{
+		"pkcs1":           pkcs1PEM,
+		"pkcs8":           pkcs8PEM,
+		"escaped newline": strings.ReplaceAll(pkcs1PEM, "\n", `\n`),
+	} {
+		got, err := ParsePrivateKey(s)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+		} else if !got.Equal(key) {
+			t.Errorf("%s: parsed a different key", name)
+		}
+	}
+
+	if _, err := ParsePrivateKey("not a key"); err == nil {
+		t.Error("expected error for non-PEM input")
+	}
+}
+
+func TestAppJWT(t *testing.T) {
+	key := testKey(t)
+	now := time.Unix(1700000000, 0)
+	c := &AppClient{AppID: "12345", Key: key, now: func() time.Time { return now }}
+
+	jwt, err := c.JWT()
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(jwt, ".")
+	if len(parts) != 3 {
+		t.Fatalf("JWT = %q", jwt)
+	}
+
+	var claims struct {
+		Iat int64  `json:"iat"`
+		Exp int64  `json:"exp"`
+		Iss string `json:"iss"`
+	}
+	payload, _ := base64.RawURLEncoding.DecodeString(parts[1])
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		t.Fatal(err)
+	}
+	if claims.Iss != "12345" || claims.Iat != now.Unix()-60 || claims.Exp != now.Unix()+540 {
+		t.Errorf("claims = %+v", claims)
+	}
+
+	sig, _ := base64.RawURLEncoding.DecodeString(parts[2])
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], sig); err != nil {
+		t.Errorf("signature: %v", err)
+	}
+}
+
+func TestInstallationToken(t *testing.T) {
+	var auths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auths = append(auths, r.Header.Get("Authorization"))
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/repos/owner/repo/installation":
+			_, _ = w.Write([]byte(`{"id": 42}`))
+		case r.Method == "POST" && r.URL.Path == "/app/installations/42/access_tokens":
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"token": "ghs_installation", "expires_at": "2024-01-01T00:00:00Z"}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	c := &AppClient{AppID: "12345", Key: testKey(t), Repo: "owner/repo", APIURL: server.URL}
+	token, err := c.InstallationToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "ghs_installation" {
+		t.Errorf("token = %q", token)
+	}
+	for _, a := range auths {
+		if !strings.HasPrefix(a, "Bearer ey") {
+			t.Errorf("Authorization = %q, want the app JWT", a)
+		}
+	}
+}
+
+func TestInstallationTokenNotInstalled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"message": "Not Found"}`))
+	}))
+	defer server.Close()
+
+	c := &AppClient{AppID: "12345", Key: testKey(t), Repo: "owner/repo", APIURL: server.URL}
+	if _, err := c.InstallationToken(); err == nil || !strings.Contains(err.Error(), "installation on owner/repo") {
+		t.Errorf("err = %v", err)
+	}
+}
@@ -0,0 +1,201 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Defaults for NewClient.
+const (
+	DefaultTimeout     = 30 * time.Second
+	DefaultMaxAttempts = 4
+	DefaultBaseDelay   = time.Second
+	DefaultMaxWait     = 5 * time.Minute
+)
+
+// secondaryRateLimitWait is how long to wait after a secondary rate limit
+// that does not say when to retry, as GitHub recommends.
+const secondaryRateLimitWait = time.Minute
+
+// Client sends GitHub API requests, retrying network errors and server
+// errors with exponential backoff and waiting out rate limits. Requests are
+// abandoned when their context is cancelled.
+//
+// A POST that reached the server may have taken effect even if its response
+// was lost, so POSTs are only retried after rate limits and after
+// connection errors that occurred before the request was sent.
+type Client struct {
+	HTTPClient  *http.Client  // defaults to http.DefaultClient
+	MaxAttempts int           // attempts per request, including the first
+	BaseDelay   time.Duration // pause before the first retry; doubles with every further one
+	MaxWait     time.Duration // longest rate-limit wait to sit out; longer limits fail the request
+
+	now   func() time.Time                                 // defaults to time.Now
+	sleep func(ctx context.Context, d time.Duration) error // defaults to sleepContext
+}
+
+// NewClient returns a Client with the given per-request timeout and the
+// default retry settings.
+func NewClient(timeout time.Duration) *Client {
+	return &Client{
+		HTTPClient:  &http.Client{Timeout: timeout},
+		MaxAttempts: DefaultMaxAttempts,
+		BaseDelay:   DefaultBaseDelay,
+		MaxWait:     DefaultMaxWait,
+	}
+}
+
+// defaultClient serves ReleaseClients without a Client of their own.
+var defaultClient = NewClient(DefaultTimeout)
+
+// Do sends req, retrying it while the failure is transient. Requests with a
+// body are only retried if req.GetBody is set, as it is for requests made
+// with http.NewRequest from a bytes.Reader. The last response or error is
+// returned once attempts run out.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	return c.do(req, replayable(req.Method), nil)
+}
+
+// replayable reports whether requests with method can be sent again after
+// they may have reached the server.
+func replayable(method string) bool {
+	return method != http.MethodPost
+}
+
+// do is Do with a hook that runs before every retry, e.g. to clean up after
+// a partially applied request. An error from beforeRetry ends the request.
+// replay allows retries after the request may have reached the server.
+func (c *Client) do(req *http.Request, replay bool, beforeRetry func() error) (*http.Response, error) {
+	ctx := req.Context()
+	delay := c.BaseDelay
+	for attempt := 1; ; attempt++ {
+		var sent bool
+		trace := &httptrace.ClientTrace{WroteRequest: func(info httptrace.WroteRequestInfo) {
+			sent = sent || info.Err == nil
+		}}
+		resp, err := c.httpClient().Do(req.WithContext(httptrace.WithClientTrace(ctx, trace)))
+		wait, retry := c.retryWait(ctx, resp, err, delay, replay || !sent)
+		if !retry || attempt >= c.MaxAttempts || (req.Body != nil && req.GetBody == nil) {
+			return resp, err
+		}
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+		}
+
+		if err := c.sleepFor(ctx, wait); err != nil {
+			return nil, err
+		}
+		delay *= 2
+		if beforeRetry != nil {
+			if err := beforeRetry(); err != nil {
+				return nil, err
+			}
+		}
+
+		next := req.Clone(ctx)
+		if req.GetBody != nil {
+			if next.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+		req = next
+	}
+}
+
+// retryWait reports whether a request is worth retrying and how long to
+// wait first. delay is the current backoff. Unless replay is set, only rate
+// limits are retried, as the server turned the request away unprocessed.
+func (c *Client) retryWait(ctx context.Context, resp *http.Response, err error, delay time.Duration, replay bool) (time.Duration, bool) {
+	switch {
+	case err != nil:
+		return delay, replay && ctx.Err() == nil
+	case resp.StatusCode >= 500:
+		return delay, replay
+	case resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusForbidden:
+		return 0, false
+	}
+
+	wait, limited := c.rateLimitWait(resp)
+	if !limited {
+		return 0, false
+	}
+	if wait < delay {
+		wait = delay
+	}
+	return wait, wait <= c.MaxWait
+}
+
+// rateLimitWait reports whether a 403 or 429 response is a rate limit and
+// how long it asks the client to wait, from Retry-After or, once the quota
+// is used up, X-RateLimit-Reset.
+func (c *Client) rateLimitWait(resp *http.Response) (time.Duration, bool) {
+	if s := resp.Header.Get("Retry-After"); s != "" {
+		if secs, err := strconv.Atoi(s); err == nil {
+			return time.Duration(secs) * time.Second, true
+		}
+		if t, err := http.ParseTime(s); err == nil {
+			return t.Sub(c.clock()), true
+		}
+	}
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		if secs, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			return time.Unix(secs, 0).Sub(c.clock()), true
+		}
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return 0, true
+	}
+
+	// Secondary rate limits are only recognisable by their message. The
+	// body is restored so that callers can still report it.
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if strings.Contains(strings.ToLower(string(body)), "secondary rate limit") {
+		return secondaryRateLimitWait, true
+	}
+	return 0, false
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient == nil {
+		return http.DefaultClient
+	}
+	return c.HTTPClient
+}
+
+func (c *Client) clock() time.Time {
+	if c.now == nil {
+		return time.Now()
+	}
+	return c.now()
+}
+
+func (c *Client) sleepFor(ctx context.Context, d time.Duration) error {
+	if c.sleep == nil {
+		return sleepContext(ctx, d)
+	}
+	return c.sleep(ctx, d)
+}
+
+// sleepContext waits for d or until ctx is cancelled.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,266 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// roundTripFunc adapts a function to http.RoundTripper.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+// testClient returns a Client that records its waits instead of sleeping.
+func testClient(waits *[]time.Duration) *Client {
+	now := time.Unix(1700000000, 0)
+	return &Client{
+		MaxAttempts: 4,
+		BaseDelay:   time.Second,
+		MaxWait:     time.Minute,
+		now:         func() time.Time { return now },
+		sleep: func(ctx context.Context, d time.Duration) error {
+			*waits = append(*waits, d)
+			return ctx.Err()
+		},
+	}
+}
+
+func TestClientRetries(t *testing.T) {
+	tests := []struct {
+		name      string
+		responses []func(w http.ResponseWriter)
+		wantCode  int
+		wantWaits []time.Duration
+	}{
+		{
+			"server errors back off",
+			[]func(w http.ResponseWriter){
+				func(w http.ResponseWriter) { w.WriteHeader(http.StatusBadGateway) },
+				func(w http.ResponseWriter) { w.WriteHeader(http.StatusServiceUnavailable) },
+				func(w http.ResponseWriter) { w.WriteHeader(http.StatusCreated) },
+			},
+			http.StatusCreated,
+			[]time.Duration{time.Second, 2 * time.Second},
+		},
+		{
+			"attempts run out",
+			[]func(w http.ResponseWriter){
+				func(w http.ResponseWriter) { w.WriteHeader(http.StatusInternalServerError) },
+			},
+			http.StatusInternalServerError,
+			[]time.Duration{time.Second, 2 * time.Second, 4 * time.Second},
+		},
+		{
+			"retry-after",
+			[]func(w http.ResponseWriter){
+				func(w http.ResponseWriter) {
+					w.Header().Set("Retry-After", "30")
+					w.WriteHeader(http.StatusTooManyRequests)
+				},
+				func(w http.ResponseWriter) { w.WriteHeader(http.StatusOK) },
+			},
+			http.StatusOK,
+			[]time.Duration{30 * time.Second},
+		},
+		{
+			"rate limit reset",
+			[]func(w http.ResponseWriter){
+				func(w http.ResponseWriter) {
+					w.Header().Set("X-RateLimit-Remaining", "0")
+					w.Header().Set("X-RateLimit-Reset", strconv.Itoa(1700000000+45))
+					w.WriteHeader(http.StatusForbidden)
+				},
+				func(w http.ResponseWriter) { w.WriteHeader(http.StatusOK) },
+			},
+			http.StatusOK,
+			[]time.Duration{45 * time.Second},
+		},
+		{
+			"secondary rate limit without headers",
+			[]func(w http.ResponseWriter){
+				func(w http.ResponseWriter) {
+					w.WriteHeader(http.StatusForbidden)
+					_, _ = w.Write([]byte(`{"message": "You have exceeded a secondary rate limit."}`))
+				},
+				func(w http.ResponseWriter) { w.WriteHeader(http.StatusOK) },
+			},
+			http.StatusOK,
+			[]time.Duration{time.Minute},
+		},
+		{
+			"rate limit longer than max wait",
+			[]func(w http.ResponseWriter){
+				func(w http.ResponseWriter) {
+					w.Header().Set("Retry-After", "3600")
+					w.WriteHeader(http.StatusTooManyRequests)
+				},
+			},
+			http.StatusTooManyRequests,
+			nil,
+		},
+		{
+			"forbidden",
+			[]func(w http.ResponseWriter){
+				func(w http.ResponseWriter) {
+					w.WriteHeader(http.StatusForbidden)
+					_, _ = w.Write([]byte(`{"message": "Resource not accessible by integration"}`))
+				},
+			},
+			http.StatusForbidden,
+			nil,
+		},
+		{
+			"client error",
+			[]func(w http.ResponseWriter){
+				func(w http.ResponseWriter) { w.WriteHeader(http.StatusNotFound) },
+			},
+			http.StatusNotFound,
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var bodies []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				bodies = append(bodies, string(body))
+				tt.responses[min(len(bodies), len(tt.responses))-1](w)
+			}))
+			defer server.Close()
+
+			var waits []time.Duration
+			client := testClient(&waits)
+			req, _ := http.NewRequest("PATCH", server.URL, strings.NewReader("payload"))
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer func() { _ = resp.Body.Close() }()
+
+			if resp.StatusCode != tt.wantCode {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantCode)
+			}
+			if len(waits) != len(tt.wantWaits) {
+				t.Fatalf("waits = %v, want %v", waits, tt.wantWaits)
+			}
+			for i := range waits {
+				if waits[i] != tt.wantWaits[i] {
+					t.Errorf("waits = %v, want %v", waits, tt.wantWaits)
+				}
+			}
+			for i, b := range bodies {
+				if b != "payload" {
+					t.Errorf("attempt %d body = %q", i+1, b)
+				}
+			}
+		})
+	}
+}
+
+func TestClientNetworkError(t *testing.T) {
+	attempts := 0
+	var waits []time.Duration
+	client := testClient(&waits)
+	client.HTTPClient = &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		attempts++
+		if attempts == 1 {
+			return nil, errors.New("connection reset")
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("")), Request: req}, nil
+	})}
+
+	req, _ := http.NewRequest("GET", "https://api.github.com/", nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if attempts != 2 || len(waits) != 1 {
+		t.Errorf("attempts = %d, waits = %v", attempts, waits)
+	}
+}
+
+func TestClientPostRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		failDial     bool
+		respond      func(w http.ResponseWriter)
+		wantAttempts int32
+	}{
+		{"server error", false, func(w http.ResponseWriter) { w.WriteHeader(http.StatusBadGateway) }, 1},
+		{"lost response", false, func(w http.ResponseWriter) {
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			_ = conn.Close()
+		}, 1},
+		{"rate limit", false, func(w http.ResponseWriter) {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}, 4},
+		{"connection refused", true, func(w http.ResponseWriter) { w.WriteHeader(http.StatusCreated) }, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The handler and the transport run on other goroutines.
+			var attempts, dials atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				attempts.Add(1)
+				tt.respond(w)
+			}))
+			defer server.Close()
+
+			var waits []time.Duration
+			client := testClient(&waits)
+			client.HTTPClient = &http.Client{Transport: &http.Transport{
+				DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+					if dials.Add(1) == 1 && tt.failDial {
+						return nil, errors.New("connection refused")
+					}
+					return (&net.Dialer{}).DialContext(ctx, network, addr)
+				},
+			}}
+
+			req, _ := http.NewRequest("POST", server.URL, strings.NewReader("payload"))
+			resp, err := client.Do(req)
+			if err == nil {
+				_ = resp.Body.Close()
+			}
+			if got := attempts.Load(); got != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", got, tt.wantAttempts)
+			}
+			if tt.failDial && (err != nil || resp.StatusCode != http.StatusCreated) {
+				t.Errorf("err = %v, want a retried request", err)
+			}
+		})
+	}
+}
+
+func TestClientContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		cancel()
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	client := &Client{MaxAttempts: 4, BaseDelay: time.Hour}
+	req, _ := http.NewRequestWithContext(ctx, "GET", server.URL, nil)
+	if _, err := client.Do(req); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+	if attempts != 1 {
+		t.Errorf("attempts = %d, want 1", attempts)
+	}
+}
@@ -0,0 +1,96 @@
+package github
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Commenter finds the pull requests behind commits and comments on and
+// labels issues and pull requests.
+type Commenter interface {
+	PullRequestsForCommit(sha string) ([]int, error)
+	ListComments(number int) ([]Comment, error)
+	CreateComment(number int, body string) error
+	UpdateComment(id int64, body string) error
+	AddLabels(number int, labels ...string) error
+}
+
+// Comment is a comment on an issue or pull request.
+type Comment struct {
+	ID   int64  `json:"id"`
+	Body string `json:"body"`
+}
+
+// PullRequestsForCommit returns the numbers of the merged pull requests that
+// contain commit sha.
+func (c *ReleaseClient) PullRequestsForCommit(sha string) ([]int, error) {
+	var pulls []struct {
+		Number   int     `json:"number"`
+		MergedAt *string `json:"merged_at"`
+	}
+	if err := c.do("GET", fmt.Sprintf("%s/repos/%s/commits/%s/pulls", c.APIURL, c.Repo, sha), nil, &pulls); err != nil {
+		return nil, fmt.Errorf("list pull requests for %s: %w", sha, err)
+	}
+	var numbers []int
+	for _, p := range pulls {
+		if p.MergedAt != nil {
+			numbers = append(numbers, p.Number)
+		}
+	}
+	return numbers, nil
+}
+
+// ListComments returns the comments on an issue or pull request, oldest
+// first.
+func (c *ReleaseClient) ListComments(number int) ([]Comment, error) {
+	var all []Comment
+	for page := 1; ; page++ {
+		var comments []Comment
+		url := fmt.Sprintf("%s/repos/%s/issues/%d/comments?per_page=100&page=%d", c.APIURL, c.Repo, number, page)
+		if err := c.do("GET", url, nil, &comments); err != nil {
+			return nil, fmt.Errorf("list comments on #%d: %w", number, err)
+		}
+		all = append(all, comments...)
+		if len(comments) < 100 {
+			return all, nil
+		}
+	}
+}
+
+// UpdateComment replaces the body of a comment.
+func (c *ReleaseClient) UpdateComment(id int64, body string) error {
+	jsonData, err := json.Marshal(map[string]string{"body": body})
+	if err != nil {
+		return fmt.Errorf("marshal comment: %w", err)
+	}
+	if err := c.do("PATCH", fmt.Sprintf("%s/repos/%s/issues/comments/%d", c.APIURL, c.Repo, id), bytes.NewReader(jsonData), nil); err != nil {
+		return fmt.Errorf("update comment %d: %w", id, err)
+	}
+	return nil
+}
+
+// CreateComment comments on an issue or pull request.
+func (c *ReleaseClient) CreateComment(number int, body string) error {
+	jsonData, err := json.Marshal(map[string]string{"body": body})
+	if err != nil {
+		return fmt.Errorf("marshal comment: %w", err)
+	}
+	if err := c.do("POST", fmt.Sprintf("%s/repos/%s/issues/%d/comments", c.APIURL, c.Repo, number), bytes.NewReader(jsonData), nil); err != nil {
+		return fmt.Errorf("comment on #%d: %w", number, err)
+	}
+	return nil
+}
+
+// AddLabels adds labels to an issue or pull request, creating labels that
+// do not exist yet.
+func (c *ReleaseClient) AddLabels(number int, labels ...string) error {
+	jsonData, err := json.Marshal(map[string][]string{"labels": labels})
+	if err != nil {
+		return fmt.Errorf("marshal labels: %w", err)
+	}
+	if err := c.do("POST", fmt.Sprintf("%s/repos/%s/issues/%d/labels", c.APIURL, c.Repo, number), bytes.NewReader(jsonData), nil); err != nil {
+		return fmt.Errorf("label #%d: %w", number, err)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package github
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestPullRequestsForCommit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Path != "/repos/owner/repo/commits/abc123/pulls" {
+			t.Errorf("request = %s %s", r.Method, r.URL.Path)
+		}
+		_, _ = w.Write([]byte(`[{"number": 4, "merged_at": "2024-01-01T00:00:00Z"}, {"number": 5, "merged_at": null}]`))
+	}))
+	defer server.Close()
+
+	client := &ReleaseClient{Token: "test-token", Repo: "owner/repo", APIURL: server.URL}
+	got, err := client.PullRequestsForCommit("abc123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(got, []int{4}) {
+		t.Errorf("PullRequestsForCommit() = %v, want [4]", got)
+	}
+}
+
+func TestCommentAndLabel(t *testing.T) {
+	var requests []string
+	var bodies []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decode body: %v", err)
+		}
+		bodies = append(bodies, body)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := &ReleaseClient{Token: "test-token", Repo: "owner/repo", APIURL: server.URL}
+	if err := client.CreateComment(7, "Released in v1.2.0"); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.AddLabels(7, "released"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"POST /repos/owner/repo/issues/7/comments", "POST /repos/owner/repo/issues/7/labels"}
+	if !slices.Equal(requests, want) {
+		t.Fatalf("requests = %v, want %v", requests, want)
+	}
+	if bodies[0]["body"] != "Released in v1.2.0" {
+		t.Errorf("comment body = %v", bodies[0])
+	}
+	if labels, _ := bodies[1]["labels"].([]any); len(labels) != 1 || labels[0] != "released" {
+		t.Errorf("labels body = %v", bodies[1])
+	}
+}
+
+func TestListAndUpdateComments(t *testing.T) {
+	var patched map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/repos/owner/repo/issues/7/comments":
+			if r.URL.Query().Get("page") == "1" {
+				comments := make([]Comment, 100)
+				for i := range comments {
+					comments[i] = Comment{ID: int64(i + 1), Body: "hi"}
+				}
+				_ = json.NewEncoder(w).Encode(comments)
+				return
+			}
+			_, _ = w.Write([]byte(`[{"id": 101, "body": "last"}]`))
+		case r.Method == "PATCH" && r.URL.Path == "/repos/owner/repo/issues/comments/101":
+			if err := json.NewDecoder(r.Body).Decode(&patched); err != nil {
+				t.Errorf("decode body: %v", err)
+			}
+			_, _ = w.Write([]byte(`{}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		}
+	}))
+	defer server.Close()
+
+	client := &ReleaseClient{Token: "test-token", Repo: "owner/repo", APIURL: server.URL}
+	comments, err := client.ListComments(7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(comments) != 101 || comments[100] != (Comment{ID: 101, Body: "last"}) {
+		t.Fatalf("ListComments() returned %d comments, last %+v", len(comments), comments[len(comments)-1])
+	}
+	if err := client.UpdateComment(101, "updated"); err != nil {
+		t.Fatal(err)
+	}
+	if patched["body"] != "updated" {
+		t.Errorf("patched = %v", patched)
+	}
+}
@@ -0,0 +1,96 @@
+package github
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Milestoner manages the repository's milestones and the issues in them.
+type Milestoner interface {
+	ListMilestones() ([]Milestone, error)
+	CreateMilestone(title string) (Milestone, error)
+	CloseMilestone(number int, title string) error
+	MilestoneIssues(number int) ([]int, error)
+	SetMilestone(issue, milestone int) error
+}
+
+// Milestone is a repository milestone.
+type Milestone struct {
+	Number int    `json:"number"`
+	Title  string `json:"title"`
+}
+
+// ListMilestones returns the open milestones.
+func (c *ReleaseClient) ListMilestones() ([]Milestone, error) {
+	var all []Milestone
+	for page := 1; ; page++ {
+		var milestones []Milestone
+		url := fmt.Sprintf("%s/repos/%s/milestones?state=open&per_page=100&page=%d", c.APIURL, c.Repo, page)
+		if err := c.do("GET", url, nil, &milestones); err != nil {
+			return nil, fmt.Errorf("list milestones: %w", err)
+		}
+		all = append(all, milestones...)
+		if len(milestones) < 100 {
+			return all, nil
+		}
+	}
+}
+
+// CreateMilestone creates an open milestone.
+func (c *ReleaseClient) CreateMilestone(title string) (Milestone, error) {
+	jsonData, err := json.Marshal(map[string]string{"title": title})
+	if err != nil {
+		return Milestone{}, fmt.Errorf("marshal milestone: %w", err)
+	}
+	var milestone Milestone
+	if err := c.do("POST", fmt.Sprintf("%s/repos/%s/milestones", c.APIURL, c.Repo), bytes.NewReader(jsonData), &milestone); err != nil {
+		return Milestone{}, fmt.Errorf("create milestone %s: %w", title, err)
+	}
+	return milestone, nil
+}
+
+// CloseMilestone closes a milestone and renames it to title.
+func (c *ReleaseClient) CloseMilestone(number int, title string) error {
+	jsonData, err := json.Marshal(map[string]string{"title": title, "state": "closed"})
+	if err != nil {
+		return fmt.Errorf("marshal milestone: %w", err)
+	}
+	if err := c.do("PATCH", fmt.Sprintf("%s/repos/%s/milestones/%d", c.APIURL, c.Repo, number), bytes.NewReader(jsonData), nil); err != nil {
+		return fmt.Errorf("close milestone %s: %w", title, err)
+	}
+	return nil
+}
+
+// MilestoneIssues returns the numbers of the open issues and pull requests
+// in a milestone.
+func (c *ReleaseClient) MilestoneIssues(number int) ([]int, error) {
+	var numbers []int
+	for page := 1; ; page++ {
+		var issues []struct {
+			Number int `json:"number"`
+		}
+		url := fmt.Sprintf("%s/repos/%s/issues?milestone=%d&state=open&per_page=100&page=%d", c.APIURL, c.Repo, number, page)
+		if err := c.do("GET", url, nil, &issues); err != nil {
+			return nil, fmt.Errorf("list issues in milestone %d: %w", number, err)
+		}
+		for _, issue := range issues {
+			numbers = append(numbers, issue.Number)
+		}
+		if len(issues) < 100 {
+			return numbers, nil
+		}
+	}
+}
+
+// SetMilestone moves an issue or pull request to a milestone.
+func (c *ReleaseClient) SetMilestone(issue, milestone int) error {
+	jsonData, err := json.Marshal(map[string]int{"milestone": milestone})
+	if err != nil {
+		return fmt.Errorf("marshal milestone: %w", err)
+	}
+	if err := c.do("PATCH", fmt.Sprintf("%s/repos/%s/issues/%d", c.APIURL, c.Repo, issue), bytes.NewReader(jsonData), nil); err != nil {
+		return fmt.Errorf("move #%d to milestone %d: %w", issue, milestone, err)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package github
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestMilestones(t *testing.T) {
+	var requests []string
+	var bodies []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		if r.Method != "GET" {
+			var body map[string]any
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Errorf("decode body: %v", err)
+			}
+			bodies = append(bodies, body)
+		}
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/repos/owner/repo/milestones":
+			if r.URL.Query().Get("state") != "open" {
+				t.Errorf("query = %s", r.URL.RawQuery)
+			}
+			_, _ = w.Write([]byte(`[{"number": 3, "title": "next"}]`))
+		case r.Method == "GET" && r.URL.Path == "/repos/owner/repo/issues":
+			if q := r.URL.Query(); q.Get("milestone") != "3" || q.Get("state") != "open" {
+				t.Errorf("query = %s", r.URL.RawQuery)
+			}
+			_, _ = w.Write([]byte(`[{"number": 8}, {"number": 9}]`))
+		case r.Method == "POST":
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"number": 4, "title": "next"}`))
+		default:
+			_, _ = w.Write([]byte(`{}`))
+		}
+	}))
+	defer server.Close()
+
+	client := &ReleaseClient{Token: "test-token", Repo: "owner/repo", APIURL: server.URL}
+	milestones, err := client.ListMilestones()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(milestones, []Milestone{{Number: 3, Title: "next"}}) {
+		t.Errorf("ListMilestones() = %+v", milestones)
+	}
+	issues, err := client.MilestoneIssues(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(issues, []int{8, 9}) {
+		t.Errorf("MilestoneIssues() = %v", issues)
+	}
+	created, err := client.CreateMilestone("next")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.Number != 4 {
+		t.Errorf("CreateMilestone() = %+v", created)
+	}
+	if err := client.SetMilestone(8, 4); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.CloseMilestone(3, "v1.2.0"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"GET /repos/owner/repo/milestones",
+		"GET /repos/owner/repo/issues",
+		"POST /repos/owner/repo/milestones",
+		"PATCH /repos/owner/repo/issues/8",
+		"PATCH /repos/owner/repo/milestones/3",
+	}
+	if !slices.Equal(requests, want) {
+		t.Fatalf("requests = %v, want %v", requests, want)
+	}
+	if bodies[0]["title"] != "next" {
+		t.Errorf("create body = %v", bodies[0])
+	}
+	if bodies[1]["milestone"] != float64(4) {
+		t.Errorf("move body = %v", bodies[1])
+	}
+	if bodies[2]["title"] != "v1.2.0" || bodies[2]["state"] != "closed" {
+		t.Errorf("close body = %v", bodies[2])
+	}
+}
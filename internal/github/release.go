@@ -2,18 +2,68 @@ package github
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
+	"strings"
 )
 
+// Releaser creates, finds and updates releases for tags, attaches assets
+// to them and drafts their notes.
+type Releaser interface {
+	CreateRelease(tag, name, body string, draft, prerelease bool) (Release, error)
+	FindRelease(tag string) (Release, error)
+	UpdateRelease(id int64, name, body string, draft, prerelease bool) (Release, error)
+	UploadAsset(release Release, name, contentType string, content []byte) error
+	ListAssets(release Release) ([]string, error)
+	GenerateNotes(tag, previousTag, target string) (string, error)
+}
+
+// Release is a GitHub release.
+type Release struct {
+	ID        int64  `json:"id"`
+	TagName   string `json:"tag_name"`
+	HTMLURL   string `json:"html_url"`
+	UploadURL string `json:"upload_url"` // URI template, e.g. ".../assets{?name,label}"
+}
+
+var (
+	// ErrReleaseExists is returned by CreateRelease when the tag already
+	// has a release.
+	ErrReleaseExists = errors.New("release already exists")
+	// ErrReleaseNotFound is returned by FindRelease when the tag has no
+	// release.
+	ErrReleaseNotFound = errors.New("release not found")
+)
+
+// apiError is an unsuccessful API response.
+type apiError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("HTTP %d: %s", e.StatusCode, e.Body)
+}
+
+// alreadyExists reports whether a response is GitHub's validation error
+// for a resource that already exists.
+func alreadyExists(status int, body string) bool {
+	return status == http.StatusUnprocessableEntity && strings.Contains(body, "already_exists")
+}
+
 // ReleaseClient creates GitHub releases via the REST API.
 type ReleaseClient struct {
-	Token  string
-	Repo   string // "owner/repo" from GITHUB_REPOSITORY
-	APIURL string // from GITHUB_API_URL, defaults to "https://api.github.com"
+	Token   string
+	Repo    string          // "owner/repo" from GITHUB_REPOSITORY
+	APIURL  string          // from GITHUB_API_URL, defaults to "https://api.github.com"
+	HTTP    *Client         // sends requests; defaults to NewClient(DefaultTimeout)
+	Context context.Context // cancels requests; defaults to context.Background()
 }
 
 // NewReleaseClient creates a client from environment variables.
@@ -39,7 +89,7 @@ type createReleaseRequest struct {
 }
 
 // CreateRelease creates a GitHub release for the given tag.
-func (c *ReleaseClient) CreateRelease(tag, name, body string, draft, prerelease bool) error {
+func (c *ReleaseClient) CreateRelease(tag, name, body string, draft, prerelease bool) (Release, error) {
 	url := fmt.Sprintf("%s/repos/%s/releases", c.APIURL, c.Repo)
 
 	payload := createReleaseRequest{
@@ -52,29 +102,219 @@ func (c *ReleaseClient) CreateRelease(tag, name, body string, draft, prerelease
 
 	jsonData, err := json.Marshal(payload)
 	if err != nil {
-		return fmt.Errorf("marshal release request: %w", err)
+		return Release{}, fmt.Errorf("marshal release request: %w", err)
+	}
+
+	var release Release
+	err = c.do("POST", url, bytes.NewReader(jsonData), &release)
+	var apiErr *apiError
+	switch {
+	case errors.As(err, &apiErr) && alreadyExists(apiErr.StatusCode, apiErr.Body):
+		return Release{}, fmt.Errorf("create release for %s: %w", tag, ErrReleaseExists)
+	case errors.As(err, &apiErr):
+		return Release{}, fmt.Errorf("create release failed (HTTP %d): %s", apiErr.StatusCode, apiErr.Body)
+	case err != nil:
+		return Release{}, fmt.Errorf("create release: %w", err)
+	}
+	return release, nil
+}
+
+// FindRelease returns the release for tag. Draft releases are not returned
+// by the tag endpoint, so the most recent releases are searched as well.
+func (c *ReleaseClient) FindRelease(tag string) (Release, error) {
+	var release Release
+	err := c.do("GET", fmt.Sprintf("%s/repos/%s/releases/tags/%s", c.APIURL, c.Repo, url.PathEscape(tag)), nil, &release)
+	var apiErr *apiError
+	switch {
+	case err == nil:
+		return release, nil
+	case !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound:
+		return Release{}, fmt.Errorf("find release for %s: %w", tag, err)
+	}
+
+	var releases []Release
+	if err := c.do("GET", fmt.Sprintf("%s/repos/%s/releases?per_page=100", c.APIURL, c.Repo), nil, &releases); err != nil {
+		return Release{}, fmt.Errorf("find release for %s: %w", tag, err)
 	}
+	for _, r := range releases {
+		if r.TagName == tag {
+			return r, nil
+		}
+	}
+	return Release{}, fmt.Errorf("find release for %s: %w", tag, ErrReleaseNotFound)
+}
+
+type updateReleaseRequest struct {
+	Name       string `json:"name"`
+	Body       string `json:"body"`
+	Draft      bool   `json:"draft"`
+	Prerelease bool   `json:"prerelease"`
+}
 
-	req, err := http.NewRequest("POST", url, bytes.NewReader(jsonData))
+// UpdateRelease replaces the name, body and flags of an existing release.
+func (c *ReleaseClient) UpdateRelease(id int64, name, body string, draft, prerelease bool) (Release, error) {
+	jsonData, err := json.Marshal(updateReleaseRequest{Name: name, Body: body, Draft: draft, Prerelease: prerelease})
 	if err != nil {
-		return fmt.Errorf("create request: %w", err)
+		return Release{}, fmt.Errorf("marshal release request: %w", err)
+	}
+	var release Release
+	if err := c.do("PATCH", fmt.Sprintf("%s/repos/%s/releases/%d", c.APIURL, c.Repo, id), bytes.NewReader(jsonData), &release); err != nil {
+		return Release{}, fmt.Errorf("update release %d: %w", id, err)
 	}
+	return release, nil
+}
 
-	req.Header.Set("Authorization", "Bearer "+c.Token)
-	req.Header.Set("Accept", "application/vnd.github+json")
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+type generateNotesRequest struct {
+	TagName         string `json:"tag_name"`
+	TargetCommitish string `json:"target_commitish,omitempty"`
+	PreviousTagName string `json:"previous_tag_name,omitempty"`
+}
+
+// GenerateNotes returns the release notes GitHub would write for tag, listing
+// the pull requests merged since previousTag, or since the last release if
+// previousTag is empty. They follow the categories in .github/release.yml.
+// target is the commit tag will point at; it need not exist yet.
+func (c *ReleaseClient) GenerateNotes(tag, previousTag, target string) (string, error) {
+	jsonData, err := json.Marshal(generateNotesRequest{TagName: tag, TargetCommitish: target, PreviousTagName: previousTag})
+	if err != nil {
+		return "", fmt.Errorf("marshal notes request: %w", err)
+	}
+	var notes struct {
+		Body string `json:"body"`
+	}
+	// Generating notes changes nothing, so it is safe to retry.
+	if err := c.send("POST", fmt.Sprintf("%s/repos/%s/releases/generate-notes", c.APIURL, c.Repo), bytes.NewReader(jsonData), &notes, true); err != nil {
+		return "", fmt.Errorf("generate release notes for %s: %w", tag, err)
+	}
+	return notes.Body, nil
+}
+
+// UploadAsset attaches a file to a release. Transient failures are retried
+// by the client. An upload that failed part-way leaves a broken asset
+// behind, which is deleted before the next attempt; so is an asset of the
+// same name from an earlier run.
+func (c *ReleaseClient) UploadAsset(release Release, name, contentType string, content []byte) error {
+	target, _, _ := strings.Cut(release.UploadURL, "{")
+	target += "?name=" + url.QueryEscape(name)
+	cleanup := func() error { return c.deleteAsset(release, name) }
+
+	for replaced := false; ; replaced = true {
+		req, err := http.NewRequestWithContext(c.ctx(), "POST", target, bytes.NewReader(content))
+		if err != nil {
+			return fmt.Errorf("upload asset %s: create request: %w", name, err)
+		}
+		c.setHeaders(req)
+		req.Header.Set("Content-Type", contentType)
+
+		resp, err := c.client().do(req, true, cleanup)
+		if err != nil {
+			return fmt.Errorf("upload asset %s: %w", name, err)
+		}
+		respBody, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		switch {
+		case resp.StatusCode < 300:
+			return nil
+		case alreadyExists(resp.StatusCode, string(respBody)) && !replaced:
+			if err := cleanup(); err != nil {
+				return fmt.Errorf("upload asset %s: %w", name, err)
+			}
+		default:
+			return fmt.Errorf("upload asset %s: HTTP %d: %s", name, resp.StatusCode, string(respBody))
+		}
+	}
+}
+
+// ListAssets returns the names of the assets that were fully uploaded to
+// release. Uploads that failed part-way are left out.
+func (c *ReleaseClient) ListAssets(release Release) ([]string, error) {
+	var assets []struct {
+		Name  string `json:"name"`
+		State string `json:"state"`
+	}
+	listURL := fmt.Sprintf("%s/repos/%s/releases/%d/assets?per_page=100", c.APIURL, c.Repo, release.ID)
+	if err := c.do("GET", listURL, nil, &assets); err != nil {
+		return nil, fmt.Errorf("list assets of release %d: %w", release.ID, err)
+	}
+	var names []string
+	for _, a := range assets {
+		if a.State == "uploaded" {
+			names = append(names, a.Name)
+		}
+	}
+	return names, nil
+}
+
+// deleteAsset removes the asset called name from release, if there is one.
+func (c *ReleaseClient) deleteAsset(release Release, name string) error {
+	var assets []struct {
+		ID   int64  `json:"id"`
+		Name string `json:"name"`
+	}
+	listURL := fmt.Sprintf("%s/repos/%s/releases/%d/assets?per_page=100", c.APIURL, c.Repo, release.ID)
+	if err := c.do("GET", listURL, nil, &assets); err != nil {
+		return fmt.Errorf("list assets: %w", err)
+	}
+	for _, a := range assets {
+		if a.Name == name {
+			deleteURL := fmt.Sprintf("%s/repos/%s/releases/assets/%d", c.APIURL, c.Repo, a.ID)
+			if err := c.do("DELETE", deleteURL, nil, nil); err != nil {
+				return fmt.Errorf("delete asset: %w", err)
+			}
+		}
+	}
+	return nil
+}
 
-	resp, err := http.DefaultClient.Do(req)
+// do sends a JSON API request and decodes the response into out, if set.
+func (c *ReleaseClient) do(method, url string, body io.Reader, out any) error {
+	return c.send(method, url, body, out, replayable(method))
+}
+
+// send is do for requests whose replay setting differs from their method's,
+// such as POSTs that change nothing.
+func (c *ReleaseClient) send(method, url string, body io.Reader, out any, replay bool) error {
+	req, err := http.NewRequestWithContext(c.ctx(), method, url, body)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	c.setHeaders(req)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.client().do(req, replay, nil)
 	if err != nil {
-		return fmt.Errorf("create release: %w", err)
+		return err
 	}
 	defer func() { _ = resp.Body.Close() }()
 
 	if resp.StatusCode >= 300 {
 		respBody, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("create release failed (HTTP %d): %s", resp.StatusCode, string(respBody))
+		return &apiError{StatusCode: resp.StatusCode, Body: string(respBody)}
+	}
+	if out == nil {
+		return nil
 	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
 
-	return nil
+func (c *ReleaseClient) client() *Client {
+	if c.HTTP == nil {
+		return defaultClient
+	}
+	return c.HTTP
+}
+
+func (c *ReleaseClient) ctx() context.Context {
+	if c.Context == nil {
+		return context.Background()
+	}
+	return c.Context
+}
+
+func (c *ReleaseClient) setHeaders(req *http.Request) {
+	req.Header.Set("Authorization", "Bearer "+c.Token)
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
 }
@@ -2,8 +2,11 @@ package github
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -25,7 +28,7 @@ func TestCreateReleaseSuccess(t *testing.T) {
 			t.Errorf("decode body: %v", err)
 		}
 		w.WriteHeader(http.StatusCreated)
-		if _, err := w.Write([]byte(`{"id": 1}`)); err != nil {
+		if _, err := w.Write([]byte(`{"id": 1, "html_url": "https://github.com/owner/repo/releases/tag/v1.2.3", "upload_url": "https://uploads.github.com/repos/owner/repo/releases/1/assets{?name,label}"}`)); err != nil {
 			t.Errorf("write response: %v", err)
 		}
 	}))
@@ -37,10 +40,18 @@ func TestCreateReleaseSuccess(t *testing.T) {
 		APIURL: server.URL,
 	}
 
-	err := client.CreateRelease("v1.2.3", "v1.2.3", "changelog", true, false)
+	release, err := client.CreateRelease("v1.2.3", "v1.2.3", "changelog", true, false)
 	if err != nil {
 		t.Fatal(err)
 	}
+	want := Release{
+		ID:        1,
+		HTMLURL:   "https://github.com/owner/repo/releases/tag/v1.2.3",
+		UploadURL: "https://uploads.github.com/repos/owner/repo/releases/1/assets{?name,label}",
+	}
+	if release != want {
+		t.Errorf("release = %+v, want %+v", release, want)
+	}
 
 	if received.TagName != "v1.2.3" {
 		t.Errorf("TagName = %q", received.TagName)
@@ -74,7 +85,7 @@ func TestCreateReleaseAPIError(t *testing.T) {
 		APIURL: server.URL,
 	}
 
-	err := client.CreateRelease("v1.0.0", "v1.0.0", "", false, false)
+	_, err := client.CreateRelease("v1.0.0", "v1.0.0", "", false, false)
 	if err == nil {
 		t.Fatal("expected error")
 	}
@@ -103,7 +114,7 @@ func TestCreateReleasePrerelease(t *testing.T) {
 		APIURL: server.URL,
 	}
 
-	err := client.CreateRelease("v1.0.0-beta", "v1.0.0-beta", "", false, true)
+	_, err := client.CreateRelease("v1.0.0-beta", "v1.0.0-beta", "", false, true)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -111,3 +122,234 @@ func TestCreateReleasePrerelease(t *testing.T) {
 		t.Error("Prerelease should be true")
 	}
 }
+
+func TestUploadAsset(t *testing.T) {
+	var gotBody, gotType, gotName string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/repos/owner/repo/releases/7/assets" {
+			t.Errorf("request = %s %s", r.Method, r.URL.Path)
+		}
+		gotName = r.URL.Query().Get("name")
+		gotType = r.Header.Get("Content-Type")
+		body, _ := io.ReadAll(r.Body)
+		gotBody = string(body)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	client := &ReleaseClient{Token: "test-token", Repo: "owner/repo", APIURL: server.URL}
+	release := Release{ID: 7, UploadURL: server.URL + "/repos/owner/repo/releases/7/assets{?name,label}"}
+	if err := client.UploadAsset(release, "app linux.tar.gz", "application/gzip", []byte("data")); err != nil {
+		t.Fatal(err)
+	}
+	if gotName != "app linux.tar.gz" || gotType != "application/gzip" || gotBody != "data" {
+		t.Errorf("uploaded name=%q type=%q body=%q", gotName, gotType, gotBody)
+	}
+}
+
+func TestUploadAssetRetry(t *testing.T) {
+	var uploads, deletes int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST":
+			uploads++
+			if uploads == 1 {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == "GET" && r.URL.Path == "/repos/owner/repo/releases/7/assets":
+			_, _ = w.Write([]byte(`[{"id": 3, "name": "other.zip"}, {"id": 4, "name": "app.zip"}]`))
+		case r.Method == "DELETE" && r.URL.Path == "/repos/owner/repo/releases/assets/4":
+			deletes++
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := &ReleaseClient{Token: "test-token", Repo: "owner/repo", APIURL: server.URL, HTTP: &Client{MaxAttempts: 3}}
+	release := Release{ID: 7, UploadURL: server.URL + "/repos/owner/repo/releases/7/assets{?name,label}"}
+	if err := client.UploadAsset(release, "app.zip", "application/zip", []byte("data")); err != nil {
+		t.Fatal(err)
+	}
+	if uploads != 2 || deletes != 1 {
+		t.Errorf("uploads = %d, deletes = %d; want 2 and 1", uploads, deletes)
+	}
+}
+
+func TestUploadAssetClientError(t *testing.T) {
+	var uploads int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		uploads++
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		_, _ = w.Write([]byte(`{"message": "Validation Failed"}`))
+	}))
+	defer server.Close()
+
+	client := &ReleaseClient{Token: "test-token", Repo: "owner/repo", APIURL: server.URL}
+	release := Release{ID: 7, UploadURL: server.URL + "/assets{?name,label}"}
+	err := client.UploadAsset(release, "app.zip", "application/zip", []byte("data"))
+	if err == nil || !strings.Contains(err.Error(), "422") {
+		t.Errorf("err = %v", err)
+	}
+	if uploads != 1 {
+		t.Errorf("uploads = %d, want no retry", uploads)
+	}
+}
+
+func TestCreateReleaseExists(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		_, _ = w.Write([]byte(`{"message": "Validation Failed", "errors": [{"resource": "Release", "code": "already_exists", "field": "tag_name"}]}`))
+	}))
+	defer server.Close()
+
+	client := &ReleaseClient{Token: "test-token", Repo: "owner/repo", APIURL: server.URL}
+	_, err := client.CreateRelease("v1.0.0", "v1.0.0", "", false, false)
+	if !errors.Is(err, ErrReleaseExists) {
+		t.Errorf("err = %v, want ErrReleaseExists", err)
+	}
+}
+
+func TestFindRelease(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/owner/repo/releases/tags/v1.0.0":
+			_, _ = w.Write([]byte(`{"id": 1, "tag_name": "v1.0.0"}`))
+		case "/repos/owner/repo/releases/tags/v1.1.0", "/repos/owner/repo/releases/tags/v9.9.9":
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message": "Not Found"}`))
+		case "/repos/owner/repo/releases":
+			_, _ = w.Write([]byte(`[{"id": 2, "tag_name": "v1.1.0"}, {"id": 1, "tag_name": "v1.0.0"}]`))
+		default:
+			t.Errorf("unexpected request %s", r.URL)
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	client := &ReleaseClient{Token: "test-token", Repo: "owner/repo", APIURL: server.URL}
+
+	if rel, err := client.FindRelease("v1.0.0"); err != nil || rel.ID != 1 {
+		t.Errorf("FindRelease(v1.0.0) = %+v, %v", rel, err)
+	}
+	// Drafts are only found in the release list.
+	if rel, err := client.FindRelease("v1.1.0"); err != nil || rel.ID != 2 {
+		t.Errorf("FindRelease(v1.1.0) = %+v, %v", rel, err)
+	}
+	if _, err := client.FindRelease("v9.9.9"); !errors.Is(err, ErrReleaseNotFound) {
+		t.Errorf("FindRelease(v9.9.9) err = %v, want ErrReleaseNotFound", err)
+	}
+}
+
+func TestUpdateRelease(t *testing.T) {
+	var received updateReleaseRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PATCH" || r.URL.Path != "/repos/owner/repo/releases/5" {
+			t.Errorf("request = %s %s", r.Method, r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("decode body: %v", err)
+		}
+		_, _ = w.Write([]byte(`{"id": 5, "tag_name": "v1.0.0", "html_url": "https://github.com/owner/repo/releases/tag/v1.0.0"}`))
+	}))
+	defer server.Close()
+
+	client := &ReleaseClient{Token: "test-token", Repo: "owner/repo", APIURL: server.URL}
+	rel, err := client.UpdateRelease(5, "v1.0.0", "new notes", false, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rel.ID != 5 || rel.HTMLURL == "" {
+		t.Errorf("release = %+v", rel)
+	}
+	if received != (updateReleaseRequest{Name: "v1.0.0", Body: "new notes", Prerelease: true}) {
+		t.Errorf("request = %+v", received)
+	}
+}
+
+func TestUploadAssetReplacesExisting(t *testing.T) {
+	exists := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "POST":
+			if exists {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				_, _ = w.Write([]byte(`{"message": "Validation Failed", "errors": [{"resource": "ReleaseAsset", "code": "already_exists", "field": "name"}]}`))
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+		case "GET":
+			_, _ = w.Write([]byte(`[{"id": 4, "name": "app.zip"}]`))
+		case "DELETE":
+			exists = false
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	client := &ReleaseClient{Token: "test-token", Repo: "owner/repo", APIURL: server.URL}
+	release := Release{ID: 7, UploadURL: server.URL + "/assets{?name,label}"}
+	if err := client.UploadAsset(release, "app.zip", "application/zip", []byte("data")); err != nil {
+		t.Fatal(err)
+	}
+	if exists {
+		t.Error("existing asset should have been deleted")
+	}
+}
+
+func TestGenerateNotes(t *testing.T) {
+	var received generateNotesRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/repos/owner/repo/releases/generate-notes" {
+			t.Errorf("request = %s %s", r.Method, r.URL.Path)
+		}
+		received = generateNotesRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("decode body: %v", err)
+		}
+		_, _ = w.Write([]byte(`{"name": "v1.1.0", "body": "## What's Changed\n* Add login by @octocat in #1"}`))
+	}))
+	defer server.Close()
+
+	client := &ReleaseClient{Token: "test-token", Repo: "owner/repo", APIURL: server.URL}
+	notes, err := client.GenerateNotes("v1.1.0", "v1.0.0", "abc123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if notes != "## What's Changed\n* Add login by @octocat in #1" {
+		t.Errorf("notes = %q", notes)
+	}
+	if received != (generateNotesRequest{TagName: "v1.1.0", TargetCommitish: "abc123", PreviousTagName: "v1.0.0"}) {
+		t.Errorf("request = %+v", received)
+	}
+
+	// The first release has no previous tag to compare with.
+	if _, err := client.GenerateNotes("v0.1.0", "", "abc123"); err != nil {
+		t.Fatal(err)
+	}
+	if received.PreviousTagName != "" {
+		t.Errorf("PreviousTagName = %q, want omitted", received.PreviousTagName)
+	}
+}
+
+func TestListAssets(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Path != "/repos/owner/repo/releases/7/assets" {
+			t.Errorf("request = %s %s", r.Method, r.URL.Path)
+		}
+		_, _ = w.Write([]byte(`[{"name": "app.zip", "state": "uploaded"}, {"name": "app.tar.gz", "state": "starter"}]`))
+	}))
+	defer server.Close()
+
+	client := &ReleaseClient{Token: "test-token", Repo: "owner/repo", APIURL: server.URL}
+	names, err := client.ListAssets(Release{ID: 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 1 || names[0] != "app.zip" {
+		t.Errorf("ListAssets() = %v, want [app.zip]", names)
+	}
+}
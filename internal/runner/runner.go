@@ -0,0 +1,1219 @@
+// Package runner implements the action's release flow: find the latest
+// version, determine the bump from commits, then tag and release.
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/netwarlan/action-semantic-versioning/internal/action"
+	"github.com/netwarlan/action-semantic-versioning/internal/assets"
+	"github.com/netwarlan/action-semantic-versioning/internal/branch"
+	"github.com/netwarlan/action-semantic-versioning/internal/calver"
+	"github.com/netwarlan/action-semantic-versioning/internal/changelog"
+	"github.com/netwarlan/action-semantic-versioning/internal/commit"
+	"github.com/netwarlan/action-semantic-versioning/internal/git"
+	"github.com/netwarlan/action-semantic-versioning/internal/github"
+	"github.com/netwarlan/action-semantic-versioning/internal/semver"
+	"github.com/netwarlan/action-semantic-versioning/internal/versionfile"
+)
+
+// defaultReleaseAuthor is the identity of release commits unless the
+// commit-user-name and commit-user-email inputs override it.
+var defaultReleaseAuthor = git.Signature{
+	Name:  "github-actions[bot]",
+	Email: "41898282+github-actions[bot]@users.noreply.github.com",
+}
+
+// maxPushAttempts bounds how often a release commit is rebased and pushed
+// again when the branch moves on in the meantime.
+const maxPushAttempts = 3
+
+// Runner computes the next version and creates its tag and release.
+type Runner struct {
+	Inputs      action.Inputs
+	Git         git.Repository
+	Releases    github.Releaser
+	Issues      github.Commenter   // announces releases on pull requests and issues
+	Milestones  github.Milestoner  // closes the milestone of a release
+	Log         io.Writer          // progress messages; defaults to os.Stdout
+	Now         func() time.Time   // clock for snapshot timestamps and CalVer; defaults to time.Now
+	Dir         string             // directory version-files are relative to; defaults to the current directory
+	PullRequest action.PullRequest // the pull request previewed with pr-preview
+}
+
+// Result is the outcome of a run.
+type Result struct {
+	PreviousVersion string
+	NewVersion      string
+	BumpType        commit.BumpType
+	Changelog       string
+	Skipped         bool
+	CommitCount     int            // commits since the previous version
+	ShortSHA        string         // abbreviated HEAD commit
+	Version         semver.Version // NewVersion parsed into its components
+	TagSHA          string         // commit the new tag points at; empty for dry runs
+	Release         github.Release // the published release, if one was created or found
+}
+
+// Output is a named action output.
+type Output struct {
+	Name  string
+	Value string
+}
+
+// Outputs returns the action outputs for the result, in a stable order.
+// Version components and release fields are empty when they do not apply.
+func (r Result) Outputs() []Output {
+	var withoutPrefix, major, minor, patch, releaseID string
+	if r.NewVersion != "" {
+		withoutPrefix = strings.TrimPrefix(r.NewVersion, r.Version.Prefix)
+		major = strconv.Itoa(r.Version.Major)
+		minor = strconv.Itoa(r.Version.Minor)
+		patch = strconv.Itoa(r.Version.Patch)
+	}
+	if r.Release.ID != 0 {
+		releaseID = strconv.FormatInt(r.Release.ID, 10)
+	}
+	return []Output{
+		{"previous-version", r.PreviousVersion},
+		{"new-version", r.NewVersion},
+		{"bump-type", r.BumpType.String()},
+		{"changelog", r.Changelog},
+		{"skipped", fmt.Sprintf("%t", r.Skipped)},
+		{"commits-since-tag", strconv.Itoa(r.CommitCount)},
+		{"short-sha", r.ShortSHA},
+		{"version-without-prefix", withoutPrefix},
+		{"major", major},
+		{"minor", minor},
+		{"patch", patch},
+		{"prerelease", r.Version.Prerelease},
+		{"tag-sha", r.TagSHA},
+		{"release-id", releaseID},
+		{"release-url", r.Release.HTMLURL},
+		{"upload-url", r.Release.UploadURL},
+	}
+}
+
+// previewMarker starts the pull request comment written by pr-preview, so
+// that later runs update it instead of adding another.
+const previewMarker = "<!-- action-semantic-versioning:preview -->"
+
+// Run executes the release flow. With pr-preview it only computes the
+// version that merging the pull request would release and reports it in a
+// comment on the pull request.
+func (r *Runner) Run() (Result, error) {
+	if !r.Inputs.PRPreview {
+		return r.run()
+	}
+	if r.PullRequest.Number == 0 {
+		return Result{}, fmt.Errorf("pr-preview needs a pull_request event")
+	}
+	result, err := r.run()
+	if err != nil {
+		return Result{}, err
+	}
+	if err := r.previewComment(result); err != nil {
+		return Result{}, fmt.Errorf("updating preview comment: %w", err)
+	}
+	return result, nil
+}
+
+func (r *Runner) run() (Result, error) {
+	inputs := r.Inputs
+
+	// Validate default version is valid semver.
+	if _, err := semver.Parse(inputs.DefaultVersion); err != nil {
+		return Result{}, fmt.Errorf("invalid default-version %q: %w", inputs.DefaultVersion, err)
+	}
+
+	parser, err := commit.NewParser(inputs.CommitConvention, inputs.CommitPattern)
+	if err != nil {
+		return Result{}, fmt.Errorf("invalid commit-convention: %w", err)
+	}
+
+	// A CalVer scheme replaces semantic bumps with date-based versions.
+	var cal *calver.Format
+	switch inputs.VersionScheme {
+	case "", "semver":
+	case "calver":
+		f, err := calver.ParseFormat(inputs.CalverFormat)
+		if err != nil {
+			return Result{}, fmt.Errorf("invalid calver-format: %w", err)
+		}
+		cal = &f
+	default:
+		return Result{}, fmt.Errorf("invalid version-scheme %q: must be semver or calver", inputs.VersionScheme)
+	}
+
+	versionFiles, err := versionfile.ParseFiles(inputs.VersionFiles)
+	if err != nil {
+		return Result{}, fmt.Errorf("invalid version-files: %w", err)
+	}
+
+	if p := inputs.ExistingRelease; p != "" && p != "fail" && p != "update" && p != "skip" {
+		return Result{}, fmt.Errorf("invalid existing-release %q: must be fail, update or skip", p)
+	}
+
+	if s := inputs.ChangelogSource; s != "" && s != "commits" && s != "github" && s != "both" {
+		return Result{}, fmt.Errorf("invalid changelog-source %q: must be commits, github or both", s)
+	}
+
+	if s := inputs.VersionSource; s != "" && s != "tag" && s != "file" {
+		return Result{}, fmt.Errorf("invalid version-source %q: must be tag or file", s)
+	}
+
+	if p := inputs.MaintenanceFeaturePolicy; p != "" && p != "fail" && p != "patch" {
+		return Result{}, fmt.Errorf("invalid maintenance-feature-policy %q: must be fail or patch", p)
+	}
+
+	// Maintenance branches such as 1.x or release/1.2.x only release
+	// versions within their range.
+	var line *semver.Range
+	if rng, ok := branch.Maintenance(inputs.Branch); ok {
+		line = &rng
+		r.logf("Maintenance branch %s: releases are limited to %s\n", inputs.Branch, rng)
+	}
+
+	// Branch rules select the release channel; without rules every branch
+	// releases stable versions.
+	channel := branch.Channel{Kind: branch.Stable}
+	if inputs.Branches != "" {
+		rules, err := branch.ParseRules(inputs.Branches)
+		if err != nil {
+			return Result{}, fmt.Errorf("invalid branches: %w", err)
+		}
+		ch, ok := rules.Match(inputs.Branch)
+		if !ok {
+			r.logf("Branch %q is not configured for releases.\n", inputs.Branch)
+			return Result{Skipped: true}, nil
+		}
+		channel = ch
+		r.logf("Release channel for %s: %s\n", inputs.Branch, channel.Kind)
+	}
+	dryRun := inputs.DryRun || channel.Kind == branch.Preview || inputs.Snapshot || inputs.PRPreview
+	prerelease := inputs.ReleasePrerelease || channel.Kind == branch.Prerelease
+
+	// Check for shallow clone.
+	shallow, err := r.Git.IsShallowRepository()
+	if err != nil {
+		return Result{}, fmt.Errorf("checking repository depth: %w", err)
+	}
+	if shallow {
+		return Result{}, fmt.Errorf("shallow clone detected — use 'actions/checkout' with 'fetch-depth: 0' to fetch full history")
+	}
+
+	// Find the latest version and the revision it was released at: the
+	// latest semver tag, or the last commit that changed the version file.
+	filter, err := r.tagFilter()
+	if err != nil {
+		return Result{}, fmt.Errorf("invalid tag-patterns: %w", err)
+	}
+	var latestTag, since string
+	if inputs.VersionSource == "file" {
+		latestTag, since, err = r.versionFromFile()
+		if err != nil {
+			return Result{}, err
+		}
+	} else {
+		filter.IncludeUnreachable = inputs.IncludeUnreachableTags
+		filter.Range = line
+		latestTag, err = r.Git.FindLatestSemverTag(filter)
+		if err != nil {
+			return Result{}, fmt.Errorf("finding latest tag: %w", err)
+		}
+		since = latestTag
+	}
+	if line != nil && latestTag == "" {
+		return Result{}, fmt.Errorf("no tags found in maintenance range %s — tag a release in the range before releasing from %s", line, inputs.Branch)
+	}
+
+	isInitial := latestTag == ""
+	previousVersion := latestTag
+	if isInitial {
+		previousVersion = ""
+		r.logf("No existing semver tags found. Will use default version: %s\n", inputs.DefaultVersion)
+	} else if inputs.VersionSource == "file" {
+		r.logf("Current version in %s: %s\n", inputs.VersionFile, latestTag)
+	} else {
+		r.logf("Latest version tag: %s\n", latestTag)
+	}
+
+	skipped := Result{PreviousVersion: previousVersion, Skipped: true}
+
+	// List commits since last tag.
+	rawCommits, err := r.Git.ListCommitsSince(since)
+	if err != nil {
+		return Result{}, fmt.Errorf("listing commits: %w", err)
+	}
+	if inputs.PRPreview {
+		if rawCommits, err = r.withoutMergeCommit(rawCommits); err != nil {
+			return Result{}, err
+		}
+	}
+
+	// Snapshots always produce a version, even without releasable commits.
+	if len(rawCommits) == 0 && !inputs.Snapshot {
+		// HEAD may carry the tag of a run that failed after tagging, in
+		// which case the remaining steps are finished now. A tag from
+		// another channel is not resumed: a stable branch at a prerelease
+		// tag releases the commits since the last stable version instead.
+		if !dryRun && latestTag != "" {
+			if r.fitsChannel(latestTag, channel) {
+				result, ok, err := r.resume(latestTag, filter, parser, prerelease)
+				if err != nil || ok {
+					return result, err
+				}
+			} else if channel.Kind == branch.Stable && inputs.VersionSource != "file" {
+				stable, match := filter, filter.Match
+				stable.Match = func(v semver.Version) bool {
+					return v.Prerelease == "" && (match == nil || match(v))
+				}
+				if since, err = r.Git.FindLatestSemverTag(stable); err != nil {
+					return Result{}, fmt.Errorf("finding latest stable tag: %w", err)
+				}
+				if rawCommits, err = r.Git.ListCommitsSince(since); err != nil {
+					return Result{}, fmt.Errorf("listing commits: %w", err)
+				}
+			}
+		}
+	}
+	if len(rawCommits) == 0 && !inputs.Snapshot {
+		r.logf("No new commits since last tag.\n")
+		return skipped, nil
+	}
+
+	r.logf("Found %d commit(s) since last tag.\n", len(rawCommits))
+
+	// Parse commits.
+	var commits []commit.ConventionalCommit
+	for _, rc := range rawCommits {
+		commits = append(commits, parser.Parse(rc.Hash, rc.Message))
+	}
+
+	// Determine bump type.
+	bumpType := commit.DetermineBump(commits, inputs.BumpPatchOnUnknown)
+
+	// A Release-As footer overrides the calculated version.
+	releaseAs := commit.ReleaseAs(commits)
+
+	if bumpType == commit.BumpNone && releaseAs == "" && !inputs.Snapshot {
+		r.logf("No version-bumping commits found.\n")
+		return skipped, nil
+	}
+
+	if line != nil && releaseAs == "" {
+		bumpType, err = r.limitToRange(*line, bumpType)
+		if err != nil {
+			return Result{}, err
+		}
+	}
+
+	// Calculate new version.
+	var newVersion semver.Version
+	switch {
+	case releaseAs != "":
+		newVersion, bumpType, err = r.resolveReleaseAs(releaseAs, latestTag, bumpType)
+		if err != nil {
+			return Result{}, err
+		}
+		r.logf("Release-As directive found: %s\n", releaseAs)
+	case isInitial && cal != nil:
+		newVersion = r.tagFormat().Apply(cal.Initial(r.now()))
+	case isInitial:
+		// Use the default version directly for the initial release.
+		base, _ := semver.Parse(inputs.DefaultVersion)
+		newVersion = r.tagFormat().Apply(base)
+	case len(rawCommits) == 0:
+		// Only snapshots get here: HEAD is the latest release itself.
+		newVersion, _ = r.parseTag(latestTag)
+	case cal != nil:
+		current, _ := r.parseTag(latestTag)
+		newVersion, err = cal.Next(current, bumpType, r.now())
+		if err != nil {
+			return Result{}, err
+		}
+	default:
+		current, _ := r.parseTag(latestTag)
+		newVersion = nextVersion(current, bumpType)
+	}
+
+	head, err := r.Git.HeadCommit()
+	if err != nil {
+		return Result{}, fmt.Errorf("resolving HEAD: %w", err)
+	}
+
+	if inputs.Snapshot {
+		newVersion = r.snapshotVersion(newVersion, len(rawCommits), head)
+		if _, err := semver.Parse(newVersion.String()); err != nil {
+			return Result{}, fmt.Errorf("invalid snapshot-identifier %q: %w", inputs.SnapshotIdentifier, err)
+		}
+	} else if newVersion.Prerelease == "" {
+		switch channel.Kind {
+		case branch.Prerelease:
+			n, err := r.nextPrereleaseNumber(newVersion, channel.Identifier)
+			if err != nil {
+				return Result{}, err
+			}
+			newVersion.Prerelease = fmt.Sprintf("%s.%d", channel.Identifier, n)
+		case branch.Preview:
+			// The g keeps an all-digit hash such as 0123456 from being read
+			// as a numeric identifier, which may not have leading zeros.
+			newVersion.Prerelease = channel.Identifier + ".g" + shortHash(head)
+		}
+	}
+
+	if line != nil && !line.Contains(newVersion) {
+		return Result{}, fmt.Errorf("version %s is outside maintenance range %s", newVersion, line)
+	}
+
+	newTag := newVersion.String()
+	if cal != nil {
+		newTag = cal.Render(newVersion)
+	}
+	changelogText, err := r.releaseNotes(commits, previousVersion, newTag, head)
+	if err != nil {
+		return Result{}, err
+	}
+
+	r.logf("Bump type: %s\n", bumpType)
+	r.logf("New version: %s\n", newTag)
+
+	if inputs.Assets != "" && !inputs.CreateRelease {
+		return Result{}, fmt.Errorf("assets need a release; set create-release to true")
+	}
+
+	var tagSHA string
+	var release github.Release
+	if !dryRun {
+		// Find the assets before tagging, so missing files fail the run
+		// before anything is published.
+		var uploads []assets.Asset
+		if inputs.Assets != "" {
+			uploads, err = assets.Collect(inputs.Assets, r.Dir)
+			if err != nil {
+				return Result{}, fmt.Errorf("invalid assets: %w", err)
+			}
+		}
+
+		// Commit the updated version files and changelog so the tag
+		// includes them.
+		if len(versionFiles) > 0 || inputs.ChangelogFile != "" {
+			head, err = r.releaseCommit(versionFiles, newVersion, cal, newTag, changelogText, parser)
+			if err != nil {
+				return Result{}, err
+			}
+		}
+
+		// Create and push tag.
+		r.logf("Creating tag %s...\n", newTag)
+		if err := r.Git.CreateTag(newTag); err != nil {
+			return Result{}, fmt.Errorf("creating tag: %w", err)
+		}
+
+		tagSHA = head
+		release, err = r.publish(newTag, changelogText, prerelease, uploads, inputs.ExistingRelease)
+		if err != nil {
+			return Result{}, err
+		}
+		r.announce(commits, newTag, release, false)
+		r.closeMilestone(newTag, prerelease, false)
+	} else {
+		r.logf("Dry run — no tag or release created.\n")
+	}
+
+	return Result{
+		PreviousVersion: previousVersion,
+		NewVersion:      newTag,
+		BumpType:        bumpType,
+		Changelog:       changelogText,
+		CommitCount:     len(rawCommits),
+		ShortSHA:        shortHash(head),
+		Version:         newVersion,
+		TagSHA:          tagSHA,
+		Release:         release,
+	}, nil
+}
+
+// releaseNotes returns the changelog for tag, which will point at target:
+// generated from commits, written by GitHub's generate-notes API, or both
+// merged, as changelog-source selects.
+func (r *Runner) releaseNotes(commits []commit.ConventionalCommit, previous, tag, target string) (string, error) {
+	generated := changelog.Generate(commits, previous, tag)
+	source := r.Inputs.ChangelogSource
+	if source == "" || source == "commits" {
+		return generated, nil
+	}
+
+	// With version-source file the previous version need not be tagged,
+	// and GitHub rejects unknown tags.
+	if previous != "" {
+		if sha, err := r.Git.TagCommit(previous); err != nil {
+			return "", fmt.Errorf("resolving tag %s: %w", previous, err)
+		} else if sha == "" {
+			previous = ""
+		}
+	}
+	notes, err := r.Releases.GenerateNotes(tag, previous, target)
+	if err != nil {
+		return "", fmt.Errorf("generating release notes: %w", err)
+	}
+	if source == "github" {
+		return notes, nil
+	}
+	return changelog.Merge(generated, notes), nil
+}
+
+// publish pushes tag and, if requested, creates its release with the
+// given assets. policy is the existing-release policy to apply, or
+// "resume" to keep an existing release but upload the assets it lacks. It
+// returns the release, or the zero Release if none was requested.
+func (r *Runner) publish(tag, body string, prerelease bool, uploads []assets.Asset, policy string) (github.Release, error) {
+	r.logf("Pushing tag %s...\n", tag)
+	if err := r.Git.PushTag(tag); err != nil {
+		return github.Release{}, fmt.Errorf("pushing tag: %w", err)
+	}
+
+	if !r.Inputs.CreateRelease {
+		return github.Release{}, nil
+	}
+	r.logf("Creating GitHub release...\n")
+	release, existing, err := r.publishRelease(tag, body, prerelease, policy)
+	if err != nil {
+		return github.Release{}, fmt.Errorf("creating release: %w", err)
+	}
+	var uploaded []string
+	switch {
+	case existing && policy == "resume" && len(uploads) > 0:
+		if uploaded, err = r.Releases.ListAssets(release); err != nil {
+			return release, fmt.Errorf("uploading assets: %w", err)
+		}
+	case existing && policy != "update":
+		return release, nil
+	}
+	return release, r.uploadAssets(release, uploads, uploaded)
+}
+
+// resume finishes the release of tag when a previous run created the tag
+// at HEAD but failed before pushing it or publishing its release. It
+// reports false if HEAD does not carry tag in the current tag format. A
+// release that already exists is left as it is unless existing-release is
+// update.
+func (r *Runner) resume(tag string, filter git.TagFilter, parser commit.Parser, prerelease bool) (Result, bool, error) {
+	version, err := r.tagFormat().Parse(tag)
+	if err != nil {
+		return Result{}, false, nil
+	}
+	head, err := r.Git.HeadCommit()
+	if err != nil {
+		return Result{}, false, fmt.Errorf("resolving HEAD: %w", err)
+	}
+	if target, err := r.Git.TagCommit(tag); err != nil {
+		return Result{}, false, fmt.Errorf("resolving tag %s: %w", tag, err)
+	} else if target != head {
+		return Result{}, false, nil
+	}
+	r.logf("HEAD is already tagged %s; resuming its release.\n", tag)
+
+	// The changelog covers the commits since the version before tag.
+	match := filter.Match
+	filter.Match = func(v semver.Version) bool {
+		return v.Compare(version) != 0 && (match == nil || match(v))
+	}
+	previous, err := r.Git.FindLatestSemverTag(filter)
+	if err != nil {
+		return Result{}, false, fmt.Errorf("finding previous tag: %w", err)
+	}
+	rawCommits, err := r.Git.ListCommitsSince(previous)
+	if err != nil {
+		return Result{}, false, fmt.Errorf("listing commits: %w", err)
+	}
+	var commits []commit.ConventionalCommit
+	for _, rc := range rawCommits {
+		commits = append(commits, parser.Parse(rc.Hash, rc.Message))
+	}
+	changelogText, err := r.releaseNotes(commits, previous, tag, head)
+	if err != nil {
+		return Result{}, false, err
+	}
+
+	var uploads []assets.Asset
+	if r.Inputs.Assets != "" && r.Inputs.CreateRelease {
+		uploads, err = assets.Collect(r.Inputs.Assets, r.Dir)
+		if err != nil {
+			return Result{}, false, fmt.Errorf("invalid assets: %w", err)
+		}
+	}
+
+	policy := r.Inputs.ExistingRelease
+	if policy != "update" {
+		policy = "resume"
+	}
+	release, err := r.publish(tag, changelogText, prerelease, uploads, policy)
+	if err != nil {
+		return Result{}, false, err
+	}
+	r.announce(commits, tag, release, true)
+	r.closeMilestone(tag, prerelease, true)
+
+	return Result{
+		PreviousVersion: previous,
+		NewVersion:      tag,
+		BumpType:        commit.DetermineBump(commits, r.Inputs.BumpPatchOnUnknown),
+		Changelog:       changelogText,
+		CommitCount:     len(rawCommits),
+		ShortSHA:        shortHash(head),
+		Version:         version,
+		TagSHA:          head,
+		Release:         release,
+	}, true, nil
+}
+
+// withoutMergeCommit drops the merge commit GitHub creates to test a pull
+// request against its base, which pull_request workflows check out. It is
+// not part of what merging the pull request releases.
+func (r *Runner) withoutMergeCommit(commits []git.RawCommit) ([]git.RawCommit, error) {
+	head, err := r.Git.HeadCommit()
+	if err != nil {
+		return nil, fmt.Errorf("resolving HEAD: %w", err)
+	}
+	if len(commits) > 0 && commits[0].Hash == head && head != r.PullRequest.HeadSHA {
+		return commits[1:], nil
+	}
+	return commits, nil
+}
+
+// previewComment creates or updates the pull request comment describing
+// what merging the pull request would release.
+func (r *Runner) previewComment(result Result) error {
+	var sb strings.Builder
+	sb.WriteString(previewMarker + "\n### Release preview\n\n")
+	switch {
+	case result.Skipped && result.PreviousVersion != "":
+		fmt.Fprintf(&sb, "Merging this pull request will not release a new version: there are no version-bumping commits since %s.\n", result.PreviousVersion)
+	case result.Skipped:
+		sb.WriteString("Merging this pull request will not release a new version.\n")
+	case result.PreviousVersion == "":
+		fmt.Fprintf(&sb, "Merging this pull request will release **%s**, the first release.\n", result.NewVersion)
+	default:
+		fmt.Fprintf(&sb, "Merging this pull request will release **%s**, a %s bump from %s.\n", result.NewVersion, result.BumpType, result.PreviousVersion)
+	}
+	if !result.Skipped {
+		fmt.Fprintf(&sb, "\n<details>\n<summary>Changelog</summary>\n\n%s\n</details>\n", strings.TrimRight(result.Changelog, "\n"))
+	}
+	body := sb.String()
+
+	number := r.PullRequest.Number
+	comments, err := r.Issues.ListComments(number)
+	if err != nil {
+		return err
+	}
+	for _, c := range comments {
+		if !strings.HasPrefix(c.Body, previewMarker) {
+			continue
+		}
+		if c.Body == body {
+			return nil
+		}
+		r.logf("Updating release preview on #%d.\n", number)
+		return r.Issues.UpdateComment(c.ID, body)
+	}
+	r.logf("Commenting release preview on #%d.\n", number)
+	return r.Issues.CreateComment(number, body)
+}
+
+// announce comments on the merged pull requests and closed issues of
+// commits that they were released in tag, and labels them, as
+// release-comments and release-label ask. Failures are only logged: the
+// release has already been published by then. A resumed run may repeat an
+// earlier announcement, so it skips those that already have the comment.
+func (r *Runner) announce(commits []commit.ConventionalCommit, tag string, release github.Release, resumed bool) {
+	if (!r.Inputs.ReleaseComments && r.Inputs.ReleaseLabel == "") || r.Issues == nil {
+		return
+	}
+
+	seen := map[int]bool{}
+	var numbers []int
+	add := func(ns []int) {
+		for _, n := range ns {
+			if !seen[n] {
+				seen[n] = true
+				numbers = append(numbers, n)
+			}
+		}
+	}
+	for _, c := range commits {
+		prs, err := r.Issues.PullRequestsForCommit(c.Hash)
+		if err != nil {
+			r.logf("Warning: %v\n", err)
+		}
+		add(prs)
+		add(c.ClosedIssues())
+	}
+	slices.Sort(numbers)
+
+	body := "Released in " + tag
+	if release.HTMLURL != "" {
+		body = fmt.Sprintf("Released in [%s](%s)", tag, release.HTMLURL)
+	}
+	for _, n := range numbers {
+		if r.Inputs.ReleaseComments && !(resumed && r.announced(n, tag)) {
+			if err := r.Issues.CreateComment(n, body); err != nil {
+				r.logf("Warning: %v\n", err)
+			}
+		}
+		if r.Inputs.ReleaseLabel != "" {
+			if err := r.Issues.AddLabels(n, r.Inputs.ReleaseLabel); err != nil {
+				r.logf("Warning: %v\n", err)
+			}
+		}
+	}
+	if len(numbers) > 0 {
+		r.logf("Announced %s on %d pull request(s) and issue(s).\n", tag, len(numbers))
+	}
+}
+
+// announced reports whether issue or pull request number already has the
+// comment announcing the release of tag.
+func (r *Runner) announced(number int, tag string) bool {
+	comments, err := r.Issues.ListComments(number)
+	if err != nil {
+		r.logf("Warning: %v\n", err)
+		return false
+	}
+	for _, c := range comments {
+		if c.Body == "Released in "+tag || strings.HasPrefix(c.Body, "Released in ["+tag+"](") {
+			return true
+		}
+	}
+	return false
+}
+
+// nextMilestone is the milestone for work planned for the next release.
+const nextMilestone = "next"
+
+// closeMilestone closes the milestone of the release of tag with the
+// milestones input. Like announce, it only logs failures.
+func (r *Runner) closeMilestone(tag string, prerelease, resumed bool) {
+	if !r.Inputs.Milestones || r.Milestones == nil || prerelease {
+		return
+	}
+	if err := r.rollMilestone(tag, resumed); err != nil {
+		r.logf("Warning: %v\n", err)
+	}
+}
+
+// rollMilestone closes the open milestone named after tag, or else the
+// "next" milestone, renaming it after tag. Its open issues move to the
+// "next" milestone, which is created if needed. A resumed run may follow one
+// that already closed the milestone, when "next" holds the work planned for
+// the following release, so it only closes a milestone named after tag.
+func (r *Runner) rollMilestone(tag string, resumed bool) error {
+	milestones, err := r.Milestones.ListMilestones()
+	if err != nil {
+		return err
+	}
+	var current, next *github.Milestone
+	for i, m := range milestones {
+		switch m.Title {
+		case tag:
+			current = &milestones[i]
+		case nextMilestone:
+			next = &milestones[i]
+		}
+	}
+	switch {
+	case current == nil && resumed:
+		return nil
+	case current == nil:
+		current, next = next, nil
+	}
+
+	var open []int
+	if current != nil {
+		if open, err = r.Milestones.MilestoneIssues(current.Number); err != nil {
+			return err
+		}
+		// Close before creating a new "next": milestone titles are unique.
+		if err := r.Milestones.CloseMilestone(current.Number, tag); err != nil {
+			return err
+		}
+		r.logf("Closed milestone %s.\n", tag)
+	}
+	if next == nil {
+		created, err := r.Milestones.CreateMilestone(nextMilestone)
+		if err != nil {
+			return err
+		}
+		next = &created
+	}
+	for _, n := range open {
+		if err := r.Milestones.SetMilestone(n, next.Number); err != nil {
+			return err
+		}
+	}
+	if len(open) > 0 {
+		r.logf("Moved %d open issue(s) to milestone %s.\n", len(open), nextMilestone)
+	}
+	return nil
+}
+
+// publishRelease creates the release for tag. If the tag already has a
+// release, the existing-release policy decides whether to fail, update it or
+// leave it as it is; existing reports that case.
+func (r *Runner) publishRelease(tag, body string, prerelease bool, policy string) (release github.Release, existing bool, err error) {
+	draft := r.Inputs.ReleaseDraft
+	release, err = r.Releases.CreateRelease(tag, tag, body, draft, prerelease)
+	switch {
+	case err == nil:
+		r.logf("Release created successfully: %s\n", release.HTMLURL)
+		return release, false, nil
+	case !errors.Is(err, github.ErrReleaseExists):
+		return github.Release{}, false, err
+	case policy != "update" && policy != "skip" && policy != "resume":
+		return github.Release{}, true, fmt.Errorf("%w; set existing-release to update or skip to re-run releases", err)
+	}
+
+	release, err = r.Releases.FindRelease(tag)
+	if err != nil {
+		return github.Release{}, true, fmt.Errorf("finding existing release: %w", err)
+	}
+	if policy != "update" {
+		r.logf("Release for %s already exists; leaving it unchanged: %s\n", tag, release.HTMLURL)
+		return release, true, nil
+	}
+
+	release, err = r.Releases.UpdateRelease(release.ID, tag, body, draft, prerelease)
+	if err != nil {
+		return github.Release{}, true, fmt.Errorf("updating existing release: %w", err)
+	}
+	r.logf("Release for %s already existed and was updated: %s\n", tag, release.HTMLURL)
+	return release, true, nil
+}
+
+// uploadAssets attaches files to release, followed by their checksums if
+// the asset-checksums input is set. Files named in uploaded are already
+// attached and are skipped.
+func (r *Runner) uploadAssets(release github.Release, files []assets.Asset, uploaded []string) error {
+	for _, a := range files {
+		if slices.Contains(uploaded, a.Name) {
+			continue
+		}
+		content, err := os.ReadFile(a.Path)
+		if err != nil {
+			return fmt.Errorf("reading asset: %w", err)
+		}
+		r.logf("Uploading %s (%s)...\n", a.Name, a.ContentType)
+		if err := r.Releases.UploadAsset(release, a.Name, a.ContentType, content); err != nil {
+			return fmt.Errorf("uploading assets: %w", err)
+		}
+	}
+
+	if len(files) == 0 || !r.Inputs.AssetChecksums || slices.Contains(uploaded, assets.ChecksumsName) {
+		return nil
+	}
+	sums, err := assets.Checksums(files)
+	if err != nil {
+		return fmt.Errorf("computing checksums: %w", err)
+	}
+	r.logf("Uploading %s...\n", assets.ChecksumsName)
+	if err := r.Releases.UploadAsset(release, assets.ChecksumsName, assets.ContentType(assets.ChecksumsName), sums); err != nil {
+		return fmt.Errorf("uploading assets: %w", err)
+	}
+	return nil
+}
+
+// versionFromFile reads the current version from the version-file input. It
+// returns the version as a tag in the current format, keeping the file's
+// formatting such as CalVer zero padding, and the commit that last changed
+// the file.
+func (r *Runner) versionFromFile() (tag, since string, err error) {
+	files, err := versionfile.ParseFiles(r.Inputs.VersionFile)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid version-file: %w", err)
+	}
+	if len(files) != 1 {
+		return "", "", fmt.Errorf("invalid version-file %q: must name exactly one file", r.Inputs.VersionFile)
+	}
+	f := files[0]
+
+	content, err := os.ReadFile(filepath.Join(r.Dir, f.Path))
+	if err != nil {
+		return "", "", fmt.Errorf("reading version file: %w", err)
+	}
+	s, err := f.Read(content)
+	if err != nil {
+		return "", "", fmt.Errorf("reading version file: %w", err)
+	}
+	v, err := semver.Parse(s)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid version %q in %s: %w", s, f.Path, err)
+	}
+
+	since, err = r.Git.LastCommitChanging(f.Path)
+	if err != nil {
+		return "", "", fmt.Errorf("finding last change to %s: %w", f.Path, err)
+	}
+	if since == "" {
+		return "", "", fmt.Errorf("version file %s has not been committed", f.Path)
+	}
+	return r.Inputs.TagPrefix + strings.TrimPrefix(s, v.Prefix) + r.Inputs.TagSuffix, since, nil
+}
+
+// releaseCommit writes v to files and the changelog to the changelog file,
+// commits them as the release commit and pushes it to the branch. It
+// returns the hash of the release commit.
+func (r *Runner) releaseCommit(files []versionfile.File, v semver.Version, cal *calver.Format, tag, changelogText string, parser commit.Parser) (string, error) {
+	if r.Inputs.Branch == "" {
+		return "", fmt.Errorf("a release commit needs a branch to push to; set the branch input")
+	}
+
+	// Project files carry the bare version, without tag prefix or suffix.
+	v.Prefix, v.Suffix = "", ""
+	version := v.String()
+	if cal != nil {
+		version = cal.Render(v)
+	}
+
+	var paths []string
+	for _, f := range files {
+		err := r.rewriteFile(f.Path, false, func(content []byte) ([]byte, error) {
+			return f.Update(content, version)
+		})
+		if err != nil {
+			return "", fmt.Errorf("updating version file: %w", err)
+		}
+		r.logf("Updated %s to %s\n", f.Path, version)
+		paths = append(paths, f.Path)
+	}
+
+	if path := r.Inputs.ChangelogFile; path != "" {
+		err := r.rewriteFile(path, true, func(content []byte) ([]byte, error) {
+			return []byte(changelog.Prepend(string(content), tag, r.now(), changelogText)), nil
+		})
+		if err != nil {
+			return "", fmt.Errorf("updating changelog file: %w", err)
+		}
+		r.logf("Added %s to %s\n", tag, path)
+		paths = append(paths, path)
+	}
+
+	base, err := r.Git.HeadCommit()
+	if err != nil {
+		return "", fmt.Errorf("resolving HEAD: %w", err)
+	}
+	author := r.releaseAuthor()
+	message := strings.ReplaceAll(r.Inputs.ReleaseCommitMessage, "{version}", tag)
+	r.logf("Committing release %s as %s...\n", tag, author.Name)
+	if _, err := r.Git.CommitFiles(message, paths, author); err != nil {
+		return "", fmt.Errorf("committing release: %w", err)
+	}
+
+	if err := r.pushReleaseCommit(author, base, parser); err != nil {
+		return "", err
+	}
+	hash, err := r.Git.HeadCommit()
+	if err != nil {
+		return "", fmt.Errorf("resolving release commit: %w", err)
+	}
+	return hash, nil
+}
+
+// pushReleaseCommit pushes HEAD, the release commit on top of base, to the
+// branch. When the branch has moved on, the release commit is rebased onto it
+// and pushed again, unless the new commits would change the released version.
+func (r *Runner) pushReleaseCommit(author git.Signature, base string, parser commit.Parser) error {
+	branchName := r.Inputs.Branch
+	for attempt := 1; ; attempt++ {
+		r.logf("Pushing release commit to %s...\n", branchName)
+		err := r.Git.PushBranch(branchName)
+		switch {
+		case err == nil:
+			return nil
+		case errors.Is(err, git.ErrProtectedBranch):
+			return fmt.Errorf("pushing release commit: %s is protected and rejected the push; allow %s to bypass its branch protection or rulesets, or use a token with that permission: %w", branchName, author.Name, err)
+		case errors.Is(err, git.ErrNonFastForward) && attempt < maxPushAttempts:
+			r.logf("%s has new commits; rebasing the release commit...\n", branchName)
+			if err := r.Git.RebaseOnRemote(branchName, author); err != nil {
+				return fmt.Errorf("rebasing release commit onto %s: %w", branchName, err)
+			}
+			if err := r.checkUpstream(base, parser); err != nil {
+				return fmt.Errorf("%s moved while releasing; re-run the release to include its new commits: %w", branchName, err)
+			}
+		default:
+			return fmt.Errorf("pushing release commit: %w", err)
+		}
+	}
+}
+
+// checkUpstream returns an error if the commits that a rebase added between
+// base and the release commit at HEAD bump the version or set Release-As.
+func (r *Runner) checkUpstream(base string, parser commit.Parser) error {
+	head, err := r.Git.HeadCommit()
+	if err != nil {
+		return fmt.Errorf("resolving HEAD: %w", err)
+	}
+	rawCommits, err := r.Git.ListCommitsSince(base)
+	if err != nil {
+		return fmt.Errorf("listing new commits: %w", err)
+	}
+	var commits []commit.ConventionalCommit
+	for _, rc := range rawCommits {
+		if rc.Hash != head {
+			commits = append(commits, parser.Parse(rc.Hash, rc.Message))
+		}
+	}
+	if bump := commit.DetermineBump(commits, r.Inputs.BumpPatchOnUnknown); bump != commit.BumpNone {
+		return fmt.Errorf("new commits need a %s bump", bump)
+	}
+	if v := commit.ReleaseAs(commits); v != "" {
+		return fmt.Errorf("new commits set Release-As %s", v)
+	}
+	return nil
+}
+
+// rewriteFile replaces the contents of path, relative to r.Dir, with the
+// result of update. If create is set, a missing file is treated as empty.
+func (r *Runner) rewriteFile(path string, create bool, update func([]byte) ([]byte, error)) error {
+	full := filepath.Join(r.Dir, path)
+	mode := os.FileMode(0644)
+	content, err := os.ReadFile(full)
+	switch {
+	case err == nil:
+		if info, err := os.Stat(full); err == nil {
+			mode = info.Mode().Perm()
+		}
+	case errors.Is(err, os.ErrNotExist) && create:
+	default:
+		return err
+	}
+	updated, err := update(content)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(full, updated, mode)
+}
+
+// releaseAuthor returns the identity release commits are made as.
+func (r *Runner) releaseAuthor() git.Signature {
+	author := defaultReleaseAuthor
+	if r.Inputs.CommitUserName != "" {
+		author.Name = r.Inputs.CommitUserName
+	}
+	if r.Inputs.CommitUserEmail != "" {
+		author.Email = r.Inputs.CommitUserEmail
+	}
+	return author
+}
+
+// fitsChannel reports whether tag could have been released on channel:
+// stable channels release versions without a prerelease, prerelease channels
+// versions with their own identifier.
+func (r *Runner) fitsChannel(tag string, channel branch.Channel) bool {
+	v, err := r.parseTag(tag)
+	if err != nil {
+		return false
+	}
+	if channel.Kind == branch.Stable {
+		return v.Prerelease == ""
+	}
+	return strings.HasPrefix(v.Prerelease, channel.Identifier+".")
+}
+
+// nextVersion applies bump to current. A prerelease already carries a pending
+// bump, so its version is only raised further when the bump requires it:
+// v1.3.0-rc.1 with a minor bump becomes v1.3.0, with a major bump v2.0.0.
+func nextVersion(current semver.Version, bump commit.BumpType) semver.Version {
+	if current.Prerelease != "" {
+		core := semver.Version{Major: current.Major, Minor: current.Minor, Patch: current.Patch, Prefix: current.Prefix, Suffix: current.Suffix}
+		switch {
+		case bump == commit.BumpMajor && (core.Minor != 0 || core.Patch != 0):
+			return core.BumpMajor()
+		case bump == commit.BumpMinor && core.Patch != 0:
+			return core.BumpMinor()
+		default:
+			return core
+		}
+	}
+
+	switch bump {
+	case commit.BumpMajor:
+		return current.BumpMajor()
+	case commit.BumpMinor:
+		return current.BumpMinor()
+	default:
+		return current.BumpPatch()
+	}
+}
+
+// nextPrereleaseNumber returns N for the next "<version>-<id>.N" prerelease,
+// one more than the highest existing tag of that form on any branch.
+func (r *Runner) nextPrereleaseNumber(v semver.Version, id string) (int, error) {
+	number := func(p semver.Version) (int, bool) {
+		if p.Major != v.Major || p.Minor != v.Minor || p.Patch != v.Patch {
+			return 0, false
+		}
+		suffix, ok := strings.CutPrefix(p.Prerelease, id+".")
+		if !ok {
+			return 0, false
+		}
+		n, err := strconv.Atoi(suffix)
+		return n, err == nil
+	}
+
+	filter, _ := r.tagFilter()
+	filter.IncludeUnreachable = true
+	filter.Match = func(p semver.Version) bool {
+		_, ok := number(p)
+		return ok
+	}
+	latest, err := r.Git.FindLatestSemverTag(filter)
+	if err != nil {
+		return 0, fmt.Errorf("finding latest prerelease tag: %w", err)
+	}
+	if latest == "" {
+		return 1, nil
+	}
+	p, _ := r.parseTag(latest)
+	n, _ := number(p)
+	return n + 1, nil
+}
+
+// snapshotVersion turns the next version into a unique, sortable snapshot
+// such as v1.5.0-dev.14+g3f2a1bc, where 14 is the number of commits since
+// the previous version. The build timestamp is added to the metadata if
+// requested. HEAD itself being the release gets only metadata.
+func (r *Runner) snapshotVersion(next semver.Version, count int, head string) semver.Version {
+	if count > 0 {
+		next.Prerelease = fmt.Sprintf("%s.%d", r.Inputs.SnapshotIdentifier, count)
+	}
+	next.Metadata = "g" + shortHash(head)
+	if r.Inputs.SnapshotTimestamp {
+		next.Metadata = r.now().UTC().Format("20060102150405") + "." + next.Metadata
+	}
+	return next
+}
+
+func (r *Runner) now() time.Time {
+	if r.Now != nil {
+		return r.Now()
+	}
+	return time.Now()
+}
+
+func shortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
+
+// limitToRange checks that bump stays within a maintenance range. Breaking
+// changes always fail; a minor bump on a range with a fixed minor version
+// fails or is downgraded to a patch according to the feature policy.
+func (r *Runner) limitToRange(line semver.Range, bump commit.BumpType) (commit.BumpType, error) {
+	switch {
+	case bump == commit.BumpMajor:
+		return bump, fmt.Errorf("breaking changes are not allowed on maintenance branch %s (range %s)", r.Inputs.Branch, line)
+	case bump == commit.BumpMinor && line.FixedMinor():
+		if r.Inputs.MaintenanceFeaturePolicy != "patch" {
+			return bump, fmt.Errorf("features are not allowed on maintenance branch %s (range %s); set maintenance-feature-policy to patch to release them as patches", r.Inputs.Branch, line)
+		}
+		r.logf("Downgrading minor bump to patch on maintenance branch %s.\n", r.Inputs.Branch)
+		return commit.BumpPatch, nil
+	}
+	return bump, nil
+}
+
+// tagFormat returns the configured tag prefix and suffix.
+func (r *Runner) tagFormat() semver.TagFormat {
+	return semver.TagFormat{Prefix: r.Inputs.TagPrefix, Suffix: r.Inputs.TagSuffix}
+}
+
+// legacyFormats parses the historical tag patterns accepted in addition to
+// the current format, one per line or separated by commas.
+func (r *Runner) legacyFormats() ([]semver.TagFormat, error) {
+	var formats []semver.TagFormat
+	for _, p := range strings.FieldsFunc(r.Inputs.TagPatterns, func(c rune) bool { return c == ',' || c == '\n' }) {
+		if p = strings.TrimSpace(p); p == "" {
+			continue
+		}
+		f, err := semver.ParseTagPattern(p)
+		if err != nil {
+			return nil, err
+		}
+		formats = append(formats, f)
+	}
+	return formats, nil
+}
+
+// tagFilter returns a filter for tags in the current or a legacy format.
+func (r *Runner) tagFilter() (git.TagFilter, error) {
+	legacy, err := r.legacyFormats()
+	return git.TagFilter{Prefix: r.Inputs.TagPrefix, Suffix: r.Inputs.TagSuffix, Legacy: legacy}, err
+}
+
+// parseTag parses a tag in the current format or a legacy format. Versions
+// parsed from legacy tags are converted to the current format so that new
+// tags derived from them use the current style.
+func (r *Runner) parseTag(tag string) (semver.Version, error) {
+	current := r.tagFormat()
+	if v, err := current.Parse(tag); err == nil {
+		return v, nil
+	}
+	legacy, _ := r.legacyFormats()
+	for _, f := range legacy {
+		if v, err := f.Parse(tag); err == nil {
+			v.Prefix = current.Prefix
+			v.Suffix = current.Suffix
+			return v, nil
+		}
+	}
+	return semver.Version{}, fmt.Errorf("tag %q does not match the tag format", tag)
+}
+
+func (r *Runner) logf(format string, args ...any) {
+	w := r.Log
+	if w == nil {
+		w = os.Stdout
+	}
+	_, _ = fmt.Fprintf(w, format, args...)
+}
+
+// resolveReleaseAs validates a Release-As version against the latest tag and
+// returns it in the current tag format, along with the bump it implies. For
+// an initial release there is nothing to compare against, so bump is
+// returned unchanged.
+func (r *Runner) resolveReleaseAs(releaseAs, latestTag string, bump commit.BumpType) (semver.Version, commit.BumpType, error) {
+	v, err := semver.Parse(releaseAs)
+	if err != nil {
+		return semver.Version{}, bump, fmt.Errorf("invalid Release-As version %q: %w", releaseAs, err)
+	}
+
+	if latestTag == "" {
+		return r.tagFormat().Apply(v), bump, nil
+	}
+
+	current, _ := r.parseTag(latestTag)
+	v.Prefix = current.Prefix
+	v.Suffix = current.Suffix
+	if v.Compare(current) <= 0 {
+		return semver.Version{}, bump, fmt.Errorf("version %s requested by Release-As must be greater than the latest version %s", v, latestTag)
+	}
+
+	bump = commit.BumpPatch
+	switch {
+	case v.Major != current.Major:
+		bump = commit.BumpMajor
+	case v.Minor != current.Minor:
+		bump = commit.BumpMinor
+	}
+	return v, bump, nil
+}
@@ -0,0 +1,1865 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/netwarlan/action-semantic-versioning/internal/action"
+	"github.com/netwarlan/action-semantic-versioning/internal/commit"
+	"github.com/netwarlan/action-semantic-versioning/internal/git"
+	"github.com/netwarlan/action-semantic-versioning/internal/git/gittest"
+	"github.com/netwarlan/action-semantic-versioning/internal/github"
+)
+
+type fakeRelease struct {
+	Tag, Name, Body   string
+	Draft, Prerelease bool
+	ID                int64
+}
+
+type fakeAsset struct {
+	ReleaseID         int64
+	Name, ContentType string
+	Content           string
+}
+
+type fakeReleaser struct {
+	Releases  []fakeRelease
+	Assets    []fakeAsset
+	Err       error
+	UploadErr error
+	Notes     string
+	NotesErr  error
+	// NotesRequests records the tag, previous tag and target of each
+	// GenerateNotes call.
+	NotesRequests [][3]string
+}
+
+func (f *fakeReleaser) CreateRelease(tag, name, body string, draft, prerelease bool) (github.Release, error) {
+	if f.Err != nil {
+		return github.Release{}, f.Err
+	}
+	if _, err := f.FindRelease(tag); err == nil {
+		return github.Release{}, fmt.Errorf("create release for %s: %w", tag, github.ErrReleaseExists)
+	}
+	id := int64(len(f.Releases) + 1)
+	f.Releases = append(f.Releases, fakeRelease{tag, name, body, draft, prerelease, id})
+	return f.release(f.Releases[len(f.Releases)-1]), nil
+}
+
+func (f *fakeReleaser) FindRelease(tag string) (github.Release, error) {
+	for _, rel := range f.Releases {
+		if rel.Tag == tag {
+			return f.release(rel), nil
+		}
+	}
+	return github.Release{}, github.ErrReleaseNotFound
+}
+
+func (f *fakeReleaser) UpdateRelease(id int64, name, body string, draft, prerelease bool) (github.Release, error) {
+	for i, rel := range f.Releases {
+		if rel.ID == id {
+			f.Releases[i] = fakeRelease{rel.Tag, name, body, draft, prerelease, id}
+			return f.release(f.Releases[i]), nil
+		}
+	}
+	return github.Release{}, fmt.Errorf("HTTP 404")
+}
+
+func (f *fakeReleaser) GenerateNotes(tag, previousTag, target string) (string, error) {
+	f.NotesRequests = append(f.NotesRequests, [3]string{tag, previousTag, target})
+	return f.Notes, f.NotesErr
+}
+
+func (f *fakeReleaser) release(rel fakeRelease) github.Release {
+	return github.Release{
+		ID:        rel.ID,
+		TagName:   rel.Tag,
+		HTMLURL:   "https://github.com/owner/repo/releases/tag/" + rel.Tag,
+		UploadURL: fmt.Sprintf("https://uploads.github.com/repos/owner/repo/releases/%d/assets{?name,label}", rel.ID),
+	}
+}
+
+func (f *fakeReleaser) UploadAsset(release github.Release, name, contentType string, content []byte) error {
+	if f.UploadErr != nil {
+		return f.UploadErr
+	}
+	f.Assets = append(f.Assets, fakeAsset{release.ID, name, contentType, string(content)})
+	return nil
+}
+
+func (f *fakeReleaser) ListAssets(release github.Release) ([]string, error) {
+	var names []string
+	for _, a := range f.Assets {
+		if a.ReleaseID == release.ID {
+			names = append(names, a.Name)
+		}
+	}
+	return names, nil
+}
+
+func defaultInputs() action.Inputs {
+	return action.Inputs{
+		Token:                "token",
+		DefaultVersion:       "v0.1.0",
+		TagPrefix:            "v",
+		CommitConvention:     "conventional",
+		ReleaseCommitMessage: "chore(release): {version}",
+	}
+}
+
+// fakeCommenter is a github.Commenter that records comments and labels.
+type fakeCommenter struct {
+	Pulls    map[string][]int // pull request numbers by commit hash
+	Existing []github.Comment // returned by ListComments
+	Comments map[int][]string
+	Updated  map[int64]string
+	Labels   map[int][]string
+	Err      error
+}
+
+func (f *fakeCommenter) PullRequestsForCommit(sha string) ([]int, error) {
+	return f.Pulls[sha], nil
+}
+
+func (f *fakeCommenter) ListComments(number int) ([]github.Comment, error) {
+	comments := slices.Clone(f.Existing)
+	for i, body := range f.Comments[number] {
+		comments = append(comments, github.Comment{ID: int64(1000 + i), Body: body})
+	}
+	return comments, f.Err
+}
+
+func (f *fakeCommenter) UpdateComment(id int64, body string) error {
+	if f.Err != nil {
+		return f.Err
+	}
+	if f.Updated == nil {
+		f.Updated = map[int64]string{}
+	}
+	f.Updated[id] = body
+	return nil
+}
+
+func (f *fakeCommenter) CreateComment(number int, body string) error {
+	if f.Err != nil {
+		return f.Err
+	}
+	if f.Comments == nil {
+		f.Comments = map[int][]string{}
+	}
+	f.Comments[number] = append(f.Comments[number], body)
+	return nil
+}
+
+func (f *fakeCommenter) AddLabels(number int, labels ...string) error {
+	if f.Err != nil {
+		return f.Err
+	}
+	if f.Labels == nil {
+		f.Labels = map[int][]string{}
+	}
+	f.Labels[number] = append(f.Labels[number], labels...)
+	return nil
+}
+
+// fakeMilestoner is a github.Milestoner over an in-memory list of
+// milestones, which keeps closed ones with their issues.
+type fakeMilestoner struct {
+	Milestones []fakeMilestone
+	Err        error
+}
+
+type fakeMilestone struct {
+	github.Milestone
+	Closed bool
+	Issues []int
+}
+
+func (f *fakeMilestoner) find(number int) *fakeMilestone {
+	for i := range f.Milestones {
+		if f.Milestones[i].Number == number {
+			return &f.Milestones[i]
+		}
+	}
+	return nil
+}
+
+func (f *fakeMilestoner) ListMilestones() ([]github.Milestone, error) {
+	var open []github.Milestone
+	for _, m := range f.Milestones {
+		if !m.Closed {
+			open = append(open, m.Milestone)
+		}
+	}
+	return open, f.Err
+}
+
+func (f *fakeMilestoner) CreateMilestone(title string) (github.Milestone, error) {
+	for _, m := range f.Milestones {
+		if m.Title == title {
+			return github.Milestone{}, errors.New("HTTP 422: already_exists")
+		}
+	}
+	m := github.Milestone{Number: len(f.Milestones) + 1, Title: title}
+	f.Milestones = append(f.Milestones, fakeMilestone{Milestone: m})
+	return m, nil
+}
+
+func (f *fakeMilestoner) CloseMilestone(number int, title string) error {
+	m := f.find(number)
+	m.Title, m.Closed = title, true
+	return nil
+}
+
+func (f *fakeMilestoner) MilestoneIssues(number int) ([]int, error) {
+	return slices.Clone(f.find(number).Issues), nil
+}
+
+func (f *fakeMilestoner) SetMilestone(issue, milestone int) error {
+	for i := range f.Milestones {
+		m := &f.Milestones[i]
+		m.Issues = slices.DeleteFunc(m.Issues, func(n int) bool { return n == issue })
+	}
+	m := f.find(milestone)
+	m.Issues = append(m.Issues, issue)
+	return nil
+}
+
+func newRunner(repo *gittest.Repo, inputs action.Inputs) (*Runner, *fakeReleaser) {
+	releaser := &fakeReleaser{}
+	return &Runner{Inputs: inputs, Git: repo, Releases: releaser, Log: io.Discard}, releaser
+}
+
+func TestRunBumps(t *testing.T) {
+	tests := []struct {
+		name     string
+		messages []string
+		want     string
+		wantBump commit.BumpType
+	}{
+		{"fix", []string{"fix: a bug"}, "v1.2.4", commit.BumpPatch},
+		{"feat", []string{"fix: a bug", "feat: a feature"}, "v1.3.0", commit.BumpMinor},
+		{"breaking", []string{"feat!: redesign", "fix: a bug"}, "v2.0.0", commit.BumpMajor},
+		{"breaking footer", []string{"refactor: rework\n\nBREAKING CHANGE: gone"}, "v2.0.0", commit.BumpMajor},
+		{"release-as", []string{"chore: launch\n\nRelease-As: 3.0.0"}, "v3.0.0", commit.BumpMajor},
+		{"bump footer", []string{"docs: launch\n\nBump: minor"}, "v1.3.0", commit.BumpMinor},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := gittest.New()
+			repo.Commit("feat: initial")
+			repo.Tag("v1.2.3")
+			for _, m := range tt.messages {
+				repo.Commit(m)
+			}
+
+			r, _ := newRunner(repo, defaultInputs())
+			result, err := r.Run()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.NewVersion != tt.want {
+				t.Errorf("NewVersion = %q, want %q", result.NewVersion, tt.want)
+			}
+			if result.BumpType != tt.wantBump {
+				t.Errorf("BumpType = %v, want %v", result.BumpType, tt.wantBump)
+			}
+			if result.PreviousVersion != "v1.2.3" {
+				t.Errorf("PreviousVersion = %q", result.PreviousVersion)
+			}
+			if repo.TagTarget(tt.want) != repo.Head() {
+				t.Errorf("tag %s not created at HEAD", tt.want)
+			}
+			if len(repo.Pushed) != 1 || repo.Pushed[0] != tt.want {
+				t.Errorf("Pushed = %v", repo.Pushed)
+			}
+		})
+	}
+}
+
+func TestRunInitialRelease(t *testing.T) {
+	repo := gittest.New()
+	repo.Commit("feat: initial")
+
+	r, _ := newRunner(repo, defaultInputs())
+	result, err := r.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.NewVersion != "v0.1.0" || result.PreviousVersion != "" || result.Skipped {
+		t.Errorf("result = %+v", result)
+	}
+}
+
+func TestRunSkipped(t *testing.T) {
+	tests := []struct {
+		name     string
+		messages []string
+	}{
+		{"no bumping commits", []string{"docs: readme", "chore: deps"}},
+		{"skip release", []string{"feat: hidden [skip release]"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := gittest.New()
+			repo.Commit("feat: initial")
+			repo.Tag("v1.0.0")
+			for _, m := range tt.messages {
+				repo.Commit(m)
+			}
+
+			r, _ := newRunner(repo, defaultInputs())
+			result, err := r.Run()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !result.Skipped || result.NewVersion != "" || result.PreviousVersion != "v1.0.0" {
+				t.Errorf("result = %+v", result)
+			}
+			if len(repo.Tags()) != 1 {
+				t.Errorf("Tags = %v", repo.Tags())
+			}
+		})
+	}
+}
+
+func TestRunDryRun(t *testing.T) {
+	repo := gittest.New()
+	repo.Commit("feat: initial")
+	repo.Tag("v1.0.0")
+	repo.Commit("feat: new")
+
+	inputs := defaultInputs()
+	inputs.DryRun = true
+	inputs.CreateRelease = true
+	r, releaser := newRunner(repo, inputs)
+	result, err := r.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.NewVersion != "v1.1.0" || result.TagSHA != "" || result.Release.ID != 0 {
+		t.Errorf("result = %+v", result)
+	}
+	if repo.TagTarget("v1.1.0") != "" || len(repo.Pushed) != 0 || len(releaser.Releases) != 0 {
+		t.Error("dry run should not tag, push or release")
+	}
+}
+
+func TestRunCreateRelease(t *testing.T) {
+	repo := gittest.New()
+	repo.Commit("feat: initial")
+	repo.Tag("v1.0.0")
+	repo.Commit("fix: a bug")
+
+	inputs := defaultInputs()
+	inputs.CreateRelease = true
+	inputs.ReleaseDraft = true
+	r, releaser := newRunner(repo, inputs)
+	if _, err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if len(releaser.Releases) != 1 {
+		t.Fatalf("Releases = %+v", releaser.Releases)
+	}
+	rel := releaser.Releases[0]
+	if rel.Tag != "v1.0.1" || !rel.Draft || rel.Prerelease {
+		t.Errorf("release = %+v", rel)
+	}
+	if !strings.Contains(rel.Body, "a bug") {
+		t.Errorf("release body = %q", rel.Body)
+	}
+}
+
+func TestRunMergedBranch(t *testing.T) {
+	repo := gittest.New()
+	repo.Commit("feat: initial")
+	repo.Tag("v1.0.0")
+	repo.Branch("topic")
+	repo.Checkout("topic")
+	repo.Commit("feat: topic feature")
+	repo.Checkout("main")
+	repo.Commit("fix: main fix")
+	repo.Merge("topic", "Merge branch 'topic'")
+
+	r, _ := newRunner(repo, defaultInputs())
+	result, err := r.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.NewVersion != "v1.1.0" {
+		t.Errorf("NewVersion = %q, want v1.1.0", result.NewVersion)
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	setup := func() *gittest.Repo {
+		repo := gittest.New()
+		repo.Commit("feat: initial")
+		repo.Tag("v1.0.0")
+		repo.Commit("fix: a bug")
+		return repo
+	}
+
+	t.Run("shallow", func(t *testing.T) {
+		repo := setup()
+		repo.Shallow = true
+		r, _ := newRunner(repo, defaultInputs())
+		if _, err := r.Run(); err == nil || !strings.Contains(err.Error(), "shallow") {
+			t.Errorf("err = %v", err)
+		}
+	})
+
+	t.Run("invalid default version", func(t *testing.T) {
+		inputs := defaultInputs()
+		inputs.DefaultVersion = "one"
+		r, _ := newRunner(setup(), inputs)
+		if _, err := r.Run(); err == nil {
+			t.Error("expected error")
+		}
+	})
+
+	t.Run("push fails", func(t *testing.T) {
+		repo := setup()
+		repo.PushTagErr = errors.New("rejected")
+		r, _ := newRunner(repo, defaultInputs())
+		if _, err := r.Run(); err == nil || !strings.Contains(err.Error(), "pushing tag") {
+			t.Errorf("err = %v", err)
+		}
+	})
+
+	t.Run("release fails", func(t *testing.T) {
+		inputs := defaultInputs()
+		inputs.CreateRelease = true
+		r, releaser := newRunner(setup(), inputs)
+		releaser.Err = errors.New("HTTP 500")
+		if _, err := r.Run(); err == nil || !strings.Contains(err.Error(), "creating release") {
+			t.Errorf("err = %v", err)
+		}
+	})
+
+	t.Run("release-as not greater", func(t *testing.T) {
+		repo := setup()
+		repo.Commit("chore: oops\n\nRelease-As: 0.9.0")
+		r, _ := newRunner(repo, defaultInputs())
+		if _, err := r.Run(); err == nil {
+			t.Error("expected error")
+		}
+	})
+}
+
+func TestResultOutputs(t *testing.T) {
+	got := Result{PreviousVersion: "v1.0.0", Skipped: true}.Outputs()
+	want := map[string]string{
+		"previous-version":       "v1.0.0",
+		"new-version":            "",
+		"bump-type":              "none",
+		"changelog":              "",
+		"skipped":                "true",
+		"commits-since-tag":      "0",
+		"short-sha":              "",
+		"version-without-prefix": "",
+		"major":                  "",
+		"minor":                  "",
+		"patch":                  "",
+		"prerelease":             "",
+		"tag-sha":                "",
+		"release-id":             "",
+		"release-url":            "",
+		"upload-url":             "",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Outputs() = %+v", got)
+	}
+	for _, o := range got {
+		if want[o.Name] != o.Value {
+			t.Errorf("output %s = %q, want %q", o.Name, o.Value, want[o.Name])
+		}
+	}
+}
+
+func TestRunIgnoresTagsOnOtherBranches(t *testing.T) {
+	repo := gittest.New()
+	repo.Commit("feat: initial")
+	repo.Tag("v2.0.0")
+	repo.Branch("release/2.x")
+	repo.Commit("feat!: v3")
+	repo.Tag("v3.0.0")
+	repo.Checkout("release/2.x")
+	repo.Commit("fix: backport")
+
+	r, _ := newRunner(repo, defaultInputs())
+	result, err := r.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.NewVersion != "v2.0.1" {
+		t.Errorf("NewVersion = %q, want v2.0.1", result.NewVersion)
+	}
+}
+
+func TestRunMaintenanceBranch(t *testing.T) {
+	setup := func() *gittest.Repo {
+		repo := gittest.New()
+		repo.Commit("feat: initial")
+		repo.Tag("v1.2.0")
+		repo.Branch("1.2.x")
+		repo.Commit("feat: one three")
+		repo.Tag("v1.3.0")
+		repo.Commit("feat!: two")
+		repo.Tag("v2.0.0")
+		repo.Checkout("1.2.x")
+		return repo
+	}
+
+	tests := []struct {
+		name     string
+		branch   string
+		policy   string
+		message  string
+		want     string
+		wantErr  string
+		wantBump commit.BumpType
+	}{
+		{"fix on minor line", "1.2.x", "fail", "fix: backport", "v1.2.1", "", commit.BumpPatch},
+		{"feat rejected", "1.2.x", "fail", "feat: backport", "", "features are not allowed", commit.BumpNone},
+		{"feat downgraded", "release/1.2.x", "patch", "feat: backport", "v1.2.1", "", commit.BumpPatch},
+		{"breaking rejected", "1.2.x", "patch", "fix!: backport", "", "breaking changes are not allowed", commit.BumpNone},
+		{"feat on major line", "1.x", "fail", "feat: backport", "v1.3.0", "", commit.BumpMinor},
+		{"release-as outside range", "1.2.x", "fail", "chore: x\n\nRelease-As: 1.4.0", "", "outside maintenance range", commit.BumpNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := setup()
+			repo.Commit(tt.message)
+
+			inputs := defaultInputs()
+			inputs.Branch = tt.branch
+			inputs.MaintenanceFeaturePolicy = tt.policy
+			inputs.DryRun = true
+			r, _ := newRunner(repo, inputs)
+			result, err := r.Run()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.NewVersion != tt.want || result.BumpType != tt.wantBump {
+				t.Errorf("result = %+v, want %s (%s)", result, tt.want, tt.wantBump)
+			}
+		})
+	}
+}
+
+func TestRunMaintenanceBranchWithoutTags(t *testing.T) {
+	repo := gittest.New()
+	repo.Commit("feat: initial")
+	repo.Tag("v2.0.0")
+	repo.Commit("fix: bug")
+
+	inputs := defaultInputs()
+	inputs.Branch = "1.x"
+	r, _ := newRunner(repo, inputs)
+	if _, err := r.Run(); err == nil || !strings.Contains(err.Error(), "no tags found") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestRunChannels(t *testing.T) {
+	const rules = "main\nnext: prerelease\nbeta: prerelease=rc\nfeat/*: preview"
+
+	tests := []struct {
+		name        string
+		branch      string
+		tags        []string
+		message     string
+		want        string
+		wantSkipped bool
+	}{
+		{"stable", "main", nil, "feat: x", "v1.1.0", false},
+		{"first prerelease", "next", nil, "feat: x", "v1.1.0-next.1", false},
+		{"numbered prerelease", "next", []string{"v1.1.0-next.1", "v1.1.0-next.9"}, "fix: x", "v1.1.0-next.10", false},
+		{"prerelease identifier", "beta", nil, "feat!: x", "v2.0.0-rc.1", false},
+		{"stable after prerelease", "main", []string{"v1.1.0-next.2"}, "fix: x", "v1.1.0", false},
+		{"prerelease raised by major", "next", []string{"v1.1.0-next.2"}, "feat!: x", "v2.0.0-next.1", false},
+		{"unconfigured branch", "develop", nil, "feat: x", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := gittest.New()
+			repo.Commit("feat: initial")
+			repo.Tag("v1.0.0")
+			for _, tag := range tt.tags {
+				repo.Commit("feat: prerelease work")
+				repo.Tag(tag)
+			}
+			repo.Commit(tt.message)
+
+			inputs := defaultInputs()
+			inputs.Branches = rules
+			inputs.Branch = tt.branch
+			inputs.CreateRelease = true
+			r, releaser := newRunner(repo, inputs)
+			result, err := r.Run()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Skipped != tt.wantSkipped || result.NewVersion != tt.want {
+				t.Fatalf("result = %+v, want %q", result, tt.want)
+			}
+			if tt.wantSkipped {
+				return
+			}
+			if len(releaser.Releases) != 1 {
+				t.Fatalf("Releases = %+v", releaser.Releases)
+			}
+			if wantPre := tt.branch != "main"; releaser.Releases[0].Prerelease != wantPre {
+				t.Errorf("release prerelease = %v, want %v", releaser.Releases[0].Prerelease, wantPre)
+			}
+		})
+	}
+}
+
+func TestRunPreviewChannel(t *testing.T) {
+	repo := gittest.New()
+	repo.Commit("feat: initial")
+	repo.Tag("v1.0.0")
+	head := repo.Commit("feat: login page")
+
+	inputs := defaultInputs()
+	inputs.Branches = "main\nfeat/*: preview"
+	inputs.Branch = "feat/Login"
+	inputs.CreateRelease = true
+	r, releaser := newRunner(repo, inputs)
+	result, err := r.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "v1.1.0-feat-login.g" + head[:7]; result.NewVersion != want {
+		t.Errorf("NewVersion = %q, want %q", result.NewVersion, want)
+	}
+	if len(repo.Tags()) != 1 || len(releaser.Releases) != 0 {
+		t.Error("preview channel should not tag or release")
+	}
+}
+
+func TestRunSnapshot(t *testing.T) {
+	now := func() time.Time { return time.Date(2026, 10, 18, 15, 30, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name      string
+		messages  []string
+		timestamp bool
+		want      string // %s is replaced with the short HEAD hash
+		wantCount int
+	}{
+		{"feature", []string{"fix: a", "feat: b"}, false, "v1.5.0-dev.2+g%s", 2},
+		{"no bumping commits", []string{"docs: a"}, false, "v1.4.1-dev.1+g%s", 1},
+		{"tagged head", nil, false, "v1.4.0+g%s", 0},
+		{"timestamp", []string{"fix: a"}, true, "v1.4.1-dev.1+20261018153000.g%s", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := gittest.New()
+			repo.Commit("feat: initial")
+			repo.Tag("v1.4.0")
+			for _, m := range tt.messages {
+				repo.Commit(m)
+			}
+
+			inputs := defaultInputs()
+			inputs.Snapshot = true
+			inputs.SnapshotIdentifier = "dev"
+			inputs.SnapshotTimestamp = tt.timestamp
+			inputs.CreateRelease = true
+			r, releaser := newRunner(repo, inputs)
+			r.Now = now
+			result, err := r.Run()
+			if err != nil {
+				t.Fatal(err)
+			}
+			short := repo.Head()[:7]
+			if want := fmt.Sprintf(tt.want, short); result.NewVersion != want {
+				t.Errorf("NewVersion = %q, want %q", result.NewVersion, want)
+			}
+			if result.Skipped || result.CommitCount != tt.wantCount || result.ShortSHA != short {
+				t.Errorf("result = %+v", result)
+			}
+			if len(repo.Tags()) != 1 || len(releaser.Releases) != 0 {
+				t.Error("snapshot should not tag or release")
+			}
+		})
+	}
+}
+
+func TestRunTagFormat(t *testing.T) {
+	tests := []struct {
+		name   string
+		prefix string
+		suffix string
+		tags   []string
+		want   string
+	}{
+		{"custom prefix", "release-", "", []string{"release-1.2.3", "v9.0.0"}, "release-1.3.0"},
+		{"path prefix", "api/v", "", []string{"api/v1.2.3", "v9.0.0"}, "api/v1.3.0"},
+		{"suffix", "v", "-lts", []string{"v1.2.3-lts", "v9.0.0"}, "v1.3.0-lts"},
+		{"initial with prefix", "release-", "", nil, "release-0.1.0"},
+		{"no prefix", "", "", []string{"1.2.3"}, "1.3.0"},
+		{"initial without prefix", "", "", nil, "0.1.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := gittest.New()
+			repo.Commit("feat: initial")
+			for _, tag := range tt.tags {
+				repo.Tag(tag)
+			}
+			repo.Commit("feat: new")
+
+			inputs := defaultInputs()
+			inputs.TagPrefix = tt.prefix
+			inputs.TagSuffix = tt.suffix
+			r, _ := newRunner(repo, inputs)
+			result, err := r.Run()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.NewVersion != tt.want {
+				t.Errorf("NewVersion = %q, want %q", result.NewVersion, tt.want)
+			}
+			if repo.TagTarget(tt.want) == "" {
+				t.Errorf("tag %s not created", tt.want)
+			}
+		})
+	}
+}
+
+func TestRunTagMigration(t *testing.T) {
+	repo := gittest.New()
+	repo.Commit("feat: initial")
+	repo.Tag("release-1.4.0")
+	repo.Commit("fix: old style")
+	repo.Tag("release-1.4.1")
+	repo.Commit("feat: migrate")
+
+	inputs := defaultInputs()
+	inputs.TagPatterns = "release-*, v*"
+	r, _ := newRunner(repo, inputs)
+	result, err := r.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.PreviousVersion != "release-1.4.1" || result.NewVersion != "v1.5.0" {
+		t.Errorf("result = %+v, want release-1.4.1 → v1.5.0", result)
+	}
+
+	// Once migrated, the newest tag wins regardless of format.
+	repo.Commit("fix: new style")
+	result, err = r.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.PreviousVersion != "v1.5.0" || result.NewVersion != "v1.5.1" {
+		t.Errorf("result = %+v, want v1.5.0 → v1.5.1", result)
+	}
+}
+
+func TestRunInvalidTagPatterns(t *testing.T) {
+	repo := gittest.New()
+	repo.Commit("feat: initial")
+
+	inputs := defaultInputs()
+	inputs.TagPatterns = "release-"
+	r, _ := newRunner(repo, inputs)
+	if _, err := r.Run(); err == nil || !strings.Contains(err.Error(), "tag-patterns") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestRunCalver(t *testing.T) {
+	now := func() time.Time { return time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name     string
+		format   string
+		tag      string
+		messages []string
+		want     string
+	}{
+		{"initial", "YYYY.MM.MICRO", "", []string{"fix: a"}, "v2026.10.0"},
+		{"same period", "YYYY.MM.MICRO", "v2026.10.0", []string{"fix: a"}, "v2026.10.1"},
+		{"new period", "YYYY.MM.MICRO", "v2026.9.4", []string{"fix: a"}, "v2026.10.0"},
+		{"padded", "YY.0M.PATCH", "v26.09.4", []string{"feat: a"}, "v26.10.0"},
+		{"minor counter", "YYYY.MINOR.MICRO", "v2026.2.3", []string{"feat: a"}, "v2026.3.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := gittest.New()
+			repo.Commit("chore: initial")
+			if tt.tag != "" {
+				repo.Tag(tt.tag)
+			}
+			for _, m := range tt.messages {
+				repo.Commit(m)
+			}
+
+			inputs := defaultInputs()
+			inputs.VersionScheme = "calver"
+			inputs.CalverFormat = tt.format
+			r, _ := newRunner(repo, inputs)
+			r.Now = now
+			result, err := r.Run()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.NewVersion != tt.want {
+				t.Errorf("NewVersion = %q, want %q", result.NewVersion, tt.want)
+			}
+			if repo.TagTarget(tt.want) != repo.Head() {
+				t.Errorf("tag %s not created at HEAD", tt.want)
+			}
+		})
+	}
+}
+
+func TestRunInvalidCalver(t *testing.T) {
+	repo := gittest.New()
+	repo.Commit("feat: initial")
+
+	for _, tt := range []struct{ scheme, format, want string }{
+		{"calver", "MAJOR.MINOR.MICRO", "calver-format"},
+		{"datever", "", "version-scheme"},
+	} {
+		inputs := defaultInputs()
+		inputs.VersionScheme = tt.scheme
+		inputs.CalverFormat = tt.format
+		r, _ := newRunner(repo, inputs)
+		if _, err := r.Run(); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s %q: err = %v", tt.scheme, tt.format, err)
+		}
+	}
+}
+
+func TestRunVersionFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"package.json": "{\n  \"name\": \"app\",\n  \"version\": \"1.2.3\"\n}\n",
+		"Chart.yaml":   "name: app\nversion: 1.2.3\nappVersion: \"1.2.3\"\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	repo := gittest.New()
+	repo.Commit("feat: initial")
+	repo.Tag("v1.2.3")
+	feature := repo.Commit("feat: a feature")
+
+	inputs := defaultInputs()
+	inputs.Branch = "main"
+	inputs.VersionFiles = "package.json\nChart.yaml"
+	r, _ := newRunner(repo, inputs)
+	r.Dir = dir
+	result, err := r.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	release := repo.Head()
+	if release == feature {
+		t.Fatal("no release commit created")
+	}
+	if msg := repo.Message(release); msg != "chore(release): v1.3.0" {
+		t.Errorf("release commit message = %q", msg)
+	}
+	if got := repo.Files(release); len(got) != 2 {
+		t.Errorf("committed files = %v", got)
+	}
+	if repo.TagTarget("v1.3.0") != release {
+		t.Error("tag should point at the release commit")
+	}
+	if len(repo.PushedBranches) != 1 || repo.PushedBranches[0] != "main" {
+		t.Errorf("PushedBranches = %v", repo.PushedBranches)
+	}
+	if result.ShortSHA != release[:7] {
+		t.Errorf("ShortSHA = %q, want release commit", result.ShortSHA)
+	}
+
+	want := map[string]string{
+		"package.json": "{\n  \"name\": \"app\",\n  \"version\": \"1.3.0\"\n}\n",
+		"Chart.yaml":   "name: app\nversion: 1.3.0\nappVersion: \"1.3.0\"\n",
+	}
+	for name, content := range want {
+		got, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != content {
+			t.Errorf("%s =\n%s\nwant\n%s", name, got, content)
+		}
+	}
+}
+
+func TestRunVersionFilesDryRun(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "version.go")
+	content := "package version\n\nconst Version = \"1.2.3\"\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := gittest.New()
+	repo.Commit("feat: initial")
+	repo.Tag("v1.2.3")
+	head := repo.Commit("fix: a bug")
+
+	inputs := defaultInputs()
+	inputs.Branch = "main"
+	inputs.DryRun = true
+	inputs.VersionFiles = "version.go"
+	r, _ := newRunner(repo, inputs)
+	r.Dir = dir
+	if _, err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if repo.Head() != head || len(repo.PushedBranches) != 0 {
+		t.Error("dry run should not commit or push")
+	}
+	if got, _ := os.ReadFile(path); string(got) != content {
+		t.Errorf("dry run modified version.go:\n%s", got)
+	}
+}
+
+func TestRunVersionFilesErrors(t *testing.T) {
+	setup := func() *gittest.Repo {
+		repo := gittest.New()
+		repo.Commit("feat: initial")
+		repo.Tag("v1.0.0")
+		repo.Commit("fix: a bug")
+		return repo
+	}
+
+	tests := []struct {
+		name   string
+		branch string
+		files  string
+		want   string
+	}{
+		{"unsupported file", "main", "setup.cfg", "version-files"},
+		{"missing file", "main", "package.json", "updating version file"},
+		{"no branch", "", "package.json", "branch"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := setup()
+			inputs := defaultInputs()
+			inputs.Branch = tt.branch
+			inputs.VersionFiles = tt.files
+			r, _ := newRunner(repo, inputs)
+			r.Dir = t.TempDir()
+			if _, err := r.Run(); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v", err)
+			}
+			if len(repo.Tags()) != 1 {
+				t.Error("no tag should be created")
+			}
+		})
+	}
+}
+
+func TestRunVersionSourceFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "package.json"), []byte(`{"version": "1.2.3"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := gittest.New()
+	repo.Commit("feat: initial")
+	repo.Tag("v9.0.0")
+	if _, err := repo.CommitFiles("chore(release): 1.2.3", []string{"package.json"}, defaultReleaseAuthor); err != nil {
+		t.Fatal(err)
+	}
+	repo.Commit("feat: a feature")
+	repo.Commit("fix: a bug")
+
+	inputs := defaultInputs()
+	inputs.VersionSource = "file"
+	inputs.VersionFile = "package.json"
+	inputs.DryRun = true
+	r, _ := newRunner(repo, inputs)
+	r.Dir = dir
+	result, err := r.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.PreviousVersion != "v1.2.3" || result.NewVersion != "v1.3.0" || result.CommitCount != 2 {
+		t.Errorf("result = %+v", result)
+	}
+}
+
+func TestRunVersionSourceFileErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		file    string
+		content string
+		want    string
+	}{
+		{"invalid source", "manifest", "VERSION", "1.0.0", "version-source"},
+		{"missing file", "file", "package.json", "", "reading version file"},
+		{"not committed", "file", "VERSION", "1.0.0\n", "has not been committed"},
+		{"invalid version", "file", "VERSION", "latest\n", "invalid version"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if tt.content != "" {
+				if err := os.WriteFile(filepath.Join(dir, tt.file), []byte(tt.content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			repo := gittest.New()
+			repo.Commit("feat: initial")
+
+			inputs := defaultInputs()
+			inputs.VersionSource = tt.source
+			inputs.VersionFile = tt.file
+			r, _ := newRunner(repo, inputs)
+			r.Dir = dir
+			if _, err := r.Run(); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v", err)
+			}
+		})
+	}
+}
+
+func TestRunReleaseCommit(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "VERSION"), []byte("1.2.3\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := gittest.New()
+	repo.Commit("feat: initial")
+	repo.Tag("v1.2.3")
+	repo.Commit("feat: login")
+	repo.Upstream = []string{"docs: concurrent change"}
+
+	inputs := defaultInputs()
+	inputs.Branch = "main"
+	inputs.VersionFiles = "VERSION"
+	inputs.ChangelogFile = "CHANGELOG.md"
+	inputs.CommitUserName = "release-bot"
+	inputs.CommitUserEmail = "release-bot@example.com"
+	r, _ := newRunner(repo, inputs)
+	r.Dir = dir
+	r.Now = func() time.Time { return time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC) }
+	if _, err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	release := repo.Head()
+	if got := repo.Author(release); got.Name != "release-bot" || got.Email != "release-bot@example.com" {
+		t.Errorf("author = %+v", got)
+	}
+	if got := repo.Files(release); len(got) != 2 || got[0] != "VERSION" || got[1] != "CHANGELOG.md" {
+		t.Errorf("committed files = %v", got)
+	}
+	if repo.TagTarget("v1.3.0") != release {
+		t.Error("tag should point at the rebased release commit")
+	}
+	if len(repo.PushedBranches) != 1 || len(repo.Upstream) != 0 {
+		t.Errorf("PushedBranches = %v, Upstream = %v", repo.PushedBranches, repo.Upstream)
+	}
+
+	got, err := os.ReadFile(filepath.Join(dir, "CHANGELOG.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(got), "# Changelog\n\n## v1.3.0 (2026-10-18)\n") || !strings.Contains(string(got), "login") {
+		t.Errorf("CHANGELOG.md =\n%s", got)
+	}
+}
+
+func TestRunReleaseCommitPushErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(repo *gittest.Repo)
+		want    string
+		wantErr error
+	}{
+		{
+			"protected branch",
+			func(repo *gittest.Repo) {
+				repo.PushBranchErr = fmt.Errorf("%w: GH006: Protected branch update failed", git.ErrProtectedBranch)
+			},
+			"bypass its branch protection",
+			git.ErrProtectedBranch,
+		},
+		{
+			"rebase fails",
+			func(repo *gittest.Repo) {
+				repo.Upstream = []string{"fix: concurrent"}
+				repo.RebaseErr = errors.New("conflict")
+			},
+			"rebasing release commit",
+			nil,
+		},
+		{
+			"upstream needs a new version",
+			func(repo *gittest.Repo) { repo.Upstream = []string{"docs: readme", "feat: concurrent"} },
+			"re-run the release",
+			nil,
+		},
+		{
+			"other push error",
+			func(repo *gittest.Repo) { repo.PushBranchErr = errors.New("network down") },
+			"pushing release commit",
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "VERSION"), []byte("1.0.0\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			repo := gittest.New()
+			repo.Commit("feat: initial")
+			repo.Tag("v1.0.0")
+			repo.Commit("fix: a bug")
+			tt.setup(repo)
+
+			inputs := defaultInputs()
+			inputs.Branch = "main"
+			inputs.VersionFiles = "VERSION"
+			r, _ := newRunner(repo, inputs)
+			r.Dir = dir
+			_, err := r.Run()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("err = %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+			if len(repo.Tags()) != 1 {
+				t.Error("no tag should be created when the release commit is not pushed")
+			}
+		})
+	}
+}
+
+func TestRunAssets(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "dist"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{"app.tar.gz": "tarball", "app.zip": "zip"} {
+		if err := os.WriteFile(filepath.Join(dir, "dist", name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	repo := gittest.New()
+	repo.Commit("feat: initial")
+	repo.Tag("v1.0.0")
+	repo.Commit("fix: a bug")
+
+	inputs := defaultInputs()
+	inputs.CreateRelease = true
+	inputs.Assets = "dist/*"
+	inputs.AssetChecksums = true
+	r, releaser := newRunner(repo, inputs)
+	r.Dir = dir
+	if _, err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(releaser.Assets) != 3 {
+		t.Fatalf("Assets = %+v", releaser.Assets)
+	}
+	want := []fakeAsset{
+		{1, "app.tar.gz", "application/gzip", "tarball"},
+		{1, "app.zip", "application/zip", "zip"},
+	}
+	for i, w := range want {
+		if releaser.Assets[i] != w {
+			t.Errorf("Assets[%d] = %+v, want %+v", i, releaser.Assets[i], w)
+		}
+	}
+	sums := releaser.Assets[2]
+	if sums.Name != "SHA256SUMS" || strings.Count(sums.Content, "\n") != 2 || !strings.Contains(sums.Content, "  app.zip\n") {
+		t.Errorf("checksums asset = %+v", sums)
+	}
+}
+
+func TestRunAssetsErrors(t *testing.T) {
+	setup := func() *gittest.Repo {
+		repo := gittest.New()
+		repo.Commit("feat: initial")
+		repo.Tag("v1.0.0")
+		repo.Commit("fix: a bug")
+		return repo
+	}
+
+	t.Run("without release", func(t *testing.T) {
+		inputs := defaultInputs()
+		inputs.Assets = "dist/*"
+		r, _ := newRunner(setup(), inputs)
+		if _, err := r.Run(); err == nil || !strings.Contains(err.Error(), "create-release") {
+			t.Errorf("err = %v", err)
+		}
+	})
+
+	t.Run("missing files", func(t *testing.T) {
+		repo := setup()
+		inputs := defaultInputs()
+		inputs.CreateRelease = true
+		inputs.Assets = "dist/*"
+		r, _ := newRunner(repo, inputs)
+		r.Dir = t.TempDir()
+		if _, err := r.Run(); err == nil || !strings.Contains(err.Error(), "no files match") {
+			t.Errorf("err = %v", err)
+		}
+		if len(repo.Tags()) != 1 {
+			t.Error("no tag should be created when assets are missing")
+		}
+	})
+
+	t.Run("upload fails", func(t *testing.T) {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "app.zip"), []byte("zip"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		inputs := defaultInputs()
+		inputs.CreateRelease = true
+		inputs.Assets = "app.zip"
+		r, releaser := newRunner(setup(), inputs)
+		r.Dir = dir
+		releaser.UploadErr = errors.New("HTTP 502")
+		if _, err := r.Run(); err == nil || !strings.Contains(err.Error(), "uploading assets") {
+			t.Errorf("err = %v", err)
+		}
+	})
+}
+
+func TestRunExistingRelease(t *testing.T) {
+	tests := []struct {
+		policy     string
+		wantErr    bool
+		wantBody   string
+		wantAssets int
+	}{
+		{"fail", true, "stale", 0},
+		{"update", false, "## What's Changed", 1},
+		{"skip", false, "stale", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "app.zip"), []byte("zip"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			repo := gittest.New()
+			repo.Commit("feat: initial")
+			repo.Tag("v1.0.0")
+			repo.Commit("fix: a bug")
+
+			inputs := defaultInputs()
+			inputs.CreateRelease = true
+			inputs.ExistingRelease = tt.policy
+			inputs.Assets = "app.zip"
+			r, releaser := newRunner(repo, inputs)
+			r.Dir = dir
+			releaser.Releases = []fakeRelease{{Tag: "v1.0.1", Name: "v1.0.1", Body: "stale", Draft: true, ID: 1}}
+
+			_, err := r.Run()
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "existing-release") {
+					t.Errorf("err = %v", err)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if len(releaser.Releases) != 1 {
+				t.Fatalf("Releases = %+v", releaser.Releases)
+			}
+			if rel := releaser.Releases[0]; !strings.HasPrefix(rel.Body, tt.wantBody) {
+				t.Errorf("release body = %q, want prefix %q", rel.Body, tt.wantBody)
+			}
+			if len(releaser.Assets) != tt.wantAssets {
+				t.Errorf("Assets = %+v", releaser.Assets)
+			}
+		})
+	}
+}
+
+func TestRunInvalidExistingRelease(t *testing.T) {
+	repo := gittest.New()
+	repo.Commit("feat: initial")
+
+	inputs := defaultInputs()
+	inputs.ExistingRelease = "replace"
+	r, _ := newRunner(repo, inputs)
+	if _, err := r.Run(); err == nil || !strings.Contains(err.Error(), "existing-release") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestRunReleaseOutputs(t *testing.T) {
+	repo := gittest.New()
+	repo.Commit("feat: initial")
+	repo.Tag("v1.0.0")
+	repo.Commit("feat!: new api")
+
+	inputs := defaultInputs()
+	inputs.CreateRelease = true
+	inputs.Branches = "main: prerelease=rc"
+	inputs.Branch = "main"
+	r, _ := newRunner(repo, inputs)
+	result, err := r.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := map[string]string{}
+	for _, o := range result.Outputs() {
+		got[o.Name] = o.Value
+	}
+	want := map[string]string{
+		"new-version":            "v2.0.0-rc.1",
+		"version-without-prefix": "2.0.0-rc.1",
+		"major":                  "2",
+		"minor":                  "0",
+		"patch":                  "0",
+		"prerelease":             "rc.1",
+		"tag-sha":                repo.Head(),
+		"release-id":             "1",
+		"release-url":            "https://github.com/owner/repo/releases/tag/v2.0.0-rc.1",
+		"upload-url":             "https://uploads.github.com/repos/owner/repo/releases/1/assets{?name,label}",
+	}
+	for name, value := range want {
+		if got[name] != value {
+			t.Errorf("output %s = %q, want %q", name, got[name], value)
+		}
+	}
+}
+
+func TestRunResume(t *testing.T) {
+	newRepo := func() *gittest.Repo {
+		repo := gittest.New()
+		repo.Commit("feat: initial")
+		repo.Tag("v1.0.0")
+		repo.Commit("feat: new thing")
+		return repo
+	}
+
+	t.Run("after push failure", func(t *testing.T) {
+		repo := newRepo()
+		inputs := defaultInputs()
+		inputs.CreateRelease = true
+		r, releaser := newRunner(repo, inputs)
+
+		repo.PushTagErr = errors.New("network down")
+		if _, err := r.Run(); err == nil {
+			t.Fatal("expected push error")
+		}
+		repo.PushTagErr = nil
+
+		result, err := r.Run()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Skipped || result.NewVersion != "v1.1.0" || result.PreviousVersion != "v1.0.0" ||
+			result.BumpType != commit.BumpMinor || result.CommitCount != 1 || !strings.Contains(result.Changelog, "new thing") {
+			t.Errorf("result = %+v", result)
+		}
+		if len(repo.Pushed) != 1 || repo.Pushed[0] != "v1.1.0" {
+			t.Errorf("Pushed = %v", repo.Pushed)
+		}
+		if len(releaser.Releases) != 1 || releaser.Releases[0].Tag != "v1.1.0" {
+			t.Errorf("Releases = %+v", releaser.Releases)
+		}
+	})
+
+	t.Run("after release failure", func(t *testing.T) {
+		repo := newRepo()
+		inputs := defaultInputs()
+		inputs.CreateRelease = true
+		r, releaser := newRunner(repo, inputs)
+
+		releaser.Err = errors.New("HTTP 502")
+		if _, err := r.Run(); err == nil {
+			t.Fatal("expected release error")
+		}
+		releaser.Err = nil
+
+		if _, err := r.Run(); err != nil {
+			t.Fatal(err)
+		}
+		if len(releaser.Releases) != 1 || releaser.Releases[0].Tag != "v1.1.0" {
+			t.Errorf("Releases = %+v", releaser.Releases)
+		}
+	})
+
+	t.Run("after upload failure", func(t *testing.T) {
+		dir := t.TempDir()
+		for _, name := range []string{"app.tar.gz", "app.zip"} {
+			if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		repo := newRepo()
+		inputs := defaultInputs()
+		inputs.CreateRelease = true
+		inputs.Assets = "app.*"
+		inputs.AssetChecksums = true
+		r, releaser := newRunner(repo, inputs)
+		r.Dir = dir
+
+		releaser.Assets = []fakeAsset{{1, "app.tar.gz", "application/gzip", "app.tar.gz"}}
+		releaser.UploadErr = errors.New("HTTP 502")
+		if _, err := r.Run(); err == nil {
+			t.Fatal("expected upload error")
+		}
+		releaser.UploadErr = nil
+
+		if _, err := r.Run(); err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, a := range releaser.Assets {
+			names = append(names, a.Name)
+		}
+		if want := []string{"app.tar.gz", "app.zip", "SHA256SUMS"}; !slices.Equal(names, want) {
+			t.Errorf("assets = %v, want %v", names, want)
+		}
+	})
+
+	t.Run("completed release", func(t *testing.T) {
+		repo := newRepo()
+		repo.Commit("fix: crash\n\nFixes #3")
+		inputs := defaultInputs()
+		inputs.CreateRelease = true
+		inputs.ReleaseComments = true
+		r, releaser := newRunner(repo, inputs)
+		issues := &fakeCommenter{}
+		r.Issues = issues
+
+		for range 2 {
+			result, err := r.Run()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.NewVersion != "v1.1.0" {
+				t.Errorf("result = %+v", result)
+			}
+		}
+		if len(releaser.Releases) != 1 {
+			t.Errorf("Releases = %+v", releaser.Releases)
+		}
+		if len(issues.Comments[3]) != 1 {
+			t.Errorf("comments on #3 = %q, want one announcement", issues.Comments[3])
+		}
+	})
+
+	// A tag from another channel is not resumed.
+	for _, tc := range []struct {
+		name, tag, branch string
+		want              string
+	}{
+		{"prerelease on stable branch", "v1.1.0-next.1", "main", "v1.1.0"},
+		{"other prerelease identifier", "v1.1.0-rc.1", "next", ""},
+		{"stable on prerelease branch", "v1.1.0", "next", ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := newRepo()
+			repo.Tag(tc.tag)
+			inputs := defaultInputs()
+			inputs.CreateRelease = true
+			inputs.Branches = "main\nnext: prerelease"
+			inputs.Branch = tc.branch
+			r, releaser := newRunner(repo, inputs)
+
+			result, err := r.Run()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.NewVersion != tc.want || len(releaser.Releases) != len(repo.Pushed) {
+				t.Errorf("result = %+v, Releases = %+v", result, releaser.Releases)
+			}
+			if tc.want != "" && !slices.Equal(repo.Pushed, []string{tc.want}) {
+				t.Errorf("Pushed = %v", repo.Pushed)
+			}
+		})
+	}
+
+	for _, tc := range []struct {
+		name   string
+		inputs func(*action.Inputs)
+	}{
+		{"dry run", func(in *action.Inputs) { in.DryRun = true }},
+		{"legacy tag", func(in *action.Inputs) { in.TagPrefix = "release-"; in.TagPatterns = "v*" }},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := newRepo()
+			repo.Tag("v1.1.0")
+			inputs := defaultInputs()
+			tc.inputs(&inputs)
+			r, _ := newRunner(repo, inputs)
+
+			result, err := r.Run()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !result.Skipped || len(repo.Pushed) != 0 {
+				t.Errorf("result = %+v, Pushed = %v", result, repo.Pushed)
+			}
+		})
+	}
+}
+
+func TestRunChangelogSource(t *testing.T) {
+	const notes = "## What's Changed\n* Add login by @octocat in #1\n"
+
+	tests := []struct {
+		source   string
+		want     []string
+		wantCall bool
+	}{
+		{"commits", []string{"### Features\n- login"}, false},
+		{"github", []string{notes}, true},
+		{"both", []string{"### Features\n- login", "### Pull Requests\n* Add login by @octocat in #1"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			repo := gittest.New()
+			repo.Commit("feat: initial")
+			repo.Tag("v1.0.0")
+			repo.Commit("feat: login")
+
+			inputs := defaultInputs()
+			inputs.CreateRelease = true
+			inputs.ChangelogSource = tt.source
+			r, releaser := newRunner(repo, inputs)
+			releaser.Notes = notes
+			head := repo.Head()
+
+			result, err := r.Run()
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, w := range tt.want {
+				if !strings.Contains(result.Changelog, w) {
+					t.Errorf("Changelog = %q, want %q", result.Changelog, w)
+				}
+			}
+			if releaser.Releases[0].Body != result.Changelog {
+				t.Errorf("release body = %q", releaser.Releases[0].Body)
+			}
+			if !tt.wantCall {
+				if len(releaser.NotesRequests) != 0 {
+					t.Errorf("GenerateNotes called: %v", releaser.NotesRequests)
+				}
+			} else if len(releaser.NotesRequests) != 1 || releaser.NotesRequests[0] != [3]string{"v1.1.0", "v1.0.0", head} {
+				t.Errorf("NotesRequests = %v", releaser.NotesRequests)
+			}
+		})
+	}
+}
+
+func TestRunChangelogSourceErrors(t *testing.T) {
+	repo := gittest.New()
+	repo.Commit("feat: initial")
+
+	inputs := defaultInputs()
+	inputs.ChangelogSource = "labels"
+	r, _ := newRunner(repo, inputs)
+	if _, err := r.Run(); err == nil || !strings.Contains(err.Error(), "changelog-source") {
+		t.Errorf("err = %v", err)
+	}
+
+	inputs.ChangelogSource = "github"
+	r, releaser := newRunner(repo, inputs)
+	releaser.NotesErr = errors.New("HTTP 404")
+	if _, err := r.Run(); err == nil || !strings.Contains(err.Error(), "generating release notes") {
+		t.Errorf("err = %v", err)
+	}
+	if len(repo.Tags()) != 0 {
+		t.Errorf("Tags = %v, want none after failing to generate notes", repo.Tags())
+	}
+}
+
+func TestRunReleaseComments(t *testing.T) {
+	repo := gittest.New()
+	repo.Commit("feat: initial")
+	repo.Tag("v1.0.0")
+	feat := repo.Commit("feat: login (#4)\n\nCloses #2")
+	fix := repo.Commit("fix: crash (#5)\n\nFixes #2, #3")
+	repo.Commit("docs: readme")
+
+	inputs := defaultInputs()
+	inputs.CreateRelease = true
+	inputs.ReleaseComments = true
+	inputs.ReleaseLabel = "released"
+	r, _ := newRunner(repo, inputs)
+	issues := &fakeCommenter{Pulls: map[string][]int{feat: {4}, fix: {5}}}
+	r.Issues = issues
+
+	if _, err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+	const want = "Released in [v1.1.0](https://github.com/owner/repo/releases/tag/v1.1.0)"
+	for _, n := range []int{2, 3, 4, 5} {
+		if c := issues.Comments[n]; len(c) != 1 || c[0] != want {
+			t.Errorf("comments on #%d = %q", n, c)
+		}
+		if l := issues.Labels[n]; len(l) != 1 || l[0] != "released" {
+			t.Errorf("labels on #%d = %q", n, l)
+		}
+	}
+	if len(issues.Comments) != 4 {
+		t.Errorf("Comments = %v", issues.Comments)
+	}
+}
+
+func TestRunReleaseCommentsBestEffort(t *testing.T) {
+	for _, dryRun := range []bool{false, true} {
+		repo := gittest.New()
+		repo.Commit("feat: initial")
+		repo.Tag("v1.0.0")
+		repo.Commit("fix: crash\n\nFixes #3")
+
+		inputs := defaultInputs()
+		inputs.DryRun = dryRun
+		inputs.ReleaseComments = true
+		r, _ := newRunner(repo, inputs)
+		issues := &fakeCommenter{}
+		if !dryRun {
+			issues.Err = errors.New("HTTP 403")
+		}
+		r.Issues = issues
+
+		result, err := r.Run()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.NewVersion != "v1.0.1" || len(issues.Comments) != 0 {
+			t.Errorf("dry run %v: result = %+v, comments = %v", dryRun, result, issues.Comments)
+		}
+	}
+}
+
+func TestRunPRPreview(t *testing.T) {
+	newRepo := func(message string) (*gittest.Repo, string) {
+		repo := gittest.New()
+		repo.Commit("feat: initial")
+		repo.Tag("v1.0.0")
+		repo.Branch("feature")
+		prHead := repo.Commit(message)
+		repo.Checkout("main")
+		repo.Merge("feature", "Merge abc into def")
+		return repo, prHead
+	}
+
+	tests := []struct {
+		name     string
+		message  string
+		existing []github.Comment
+		want     string
+		wantSkip bool
+	}{
+		{"breaking", "feat!: new api", nil, "will release **v2.0.0**, a major bump from v1.0.0", false},
+		{"no bump", "docs: readme", nil, "will not release a new version: there are no version-bumping commits since v1.0.0", true},
+		{"updates sticky comment", "fix: crash", []github.Comment{{ID: 1, Body: "LGTM"}, {ID: 9, Body: previewMarker + "\nold"}}, "will release **v1.0.1**", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, prHead := newRepo(tt.message)
+			inputs := defaultInputs()
+			inputs.PRPreview = true
+			inputs.CreateRelease = true
+			r, releaser := newRunner(repo, inputs)
+			issues := &fakeCommenter{Existing: tt.existing}
+			r.Issues = issues
+			r.PullRequest = action.PullRequest{Number: 12, BaseRef: "main", HeadSHA: prHead}
+
+			result, err := r.Run()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Skipped != tt.wantSkip || (!tt.wantSkip && result.CommitCount != 1) {
+				t.Errorf("result = %+v", result)
+			}
+			if len(repo.Tags()) != 1 || len(releaser.Releases) != 0 {
+				t.Errorf("preview should not tag or release: Tags = %v", repo.Tags())
+			}
+
+			var body string
+			if tt.existing != nil {
+				if len(issues.Comments) != 0 || len(issues.Updated) != 1 {
+					t.Fatalf("Comments = %v, Updated = %v", issues.Comments, issues.Updated)
+				}
+				body = issues.Updated[9]
+			} else {
+				if len(issues.Comments[12]) != 1 {
+					t.Fatalf("Comments = %v", issues.Comments)
+				}
+				body = issues.Comments[12][0]
+			}
+			if !strings.HasPrefix(body, previewMarker) || !strings.Contains(body, tt.want) {
+				t.Errorf("comment = %q, want %q", body, tt.want)
+			}
+			if strings.Contains(body, "Merge abc") {
+				t.Errorf("comment lists the merge commit: %q", body)
+			}
+		})
+	}
+}
+
+func TestRunPRPreviewUnchanged(t *testing.T) {
+	repo := gittest.New()
+	repo.Commit("feat: initial")
+	inputs := defaultInputs()
+	inputs.PRPreview = true
+	r, _ := newRunner(repo, inputs)
+	issues := &fakeCommenter{}
+	r.Issues = issues
+
+	if _, err := r.Run(); err == nil || !strings.Contains(err.Error(), "pull_request event") {
+		t.Errorf("err = %v", err)
+	}
+
+	r.PullRequest = action.PullRequest{Number: 3, HeadSHA: repo.Head()}
+	if _, err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+	issues.Existing = []github.Comment{{ID: 5, Body: issues.Comments[3][0]}}
+	if _, err := r.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if len(issues.Comments[3]) != 1 || len(issues.Updated) != 0 {
+		t.Errorf("unchanged preview rewritten: Comments = %v, Updated = %v", issues.Comments, issues.Updated)
+	}
+}
+
+func TestRunMilestones(t *testing.T) {
+	tests := []struct {
+		name       string
+		milestones []fakeMilestone
+		prerelease bool
+		want       []fakeMilestone
+	}{
+		{
+			name: "version milestone",
+			milestones: []fakeMilestone{
+				{Milestone: github.Milestone{Number: 1, Title: "v1.1.0"}, Issues: []int{7, 8}},
+				{Milestone: github.Milestone{Number: 2, Title: "next"}, Issues: []int{9}},
+			},
+			want: []fakeMilestone{
+				{Milestone: github.Milestone{Number: 1, Title: "v1.1.0"}, Closed: true},
+				{Milestone: github.Milestone{Number: 2, Title: "next"}, Issues: []int{9, 7, 8}},
+			},
+		},
+		{
+			name: "next milestone",
+			milestones: []fakeMilestone{
+				{Milestone: github.Milestone{Number: 1, Title: "next"}, Issues: []int{7}},
+			},
+			want: []fakeMilestone{
+				{Milestone: github.Milestone{Number: 1, Title: "v1.1.0"}, Closed: true},
+				{Milestone: github.Milestone{Number: 2, Title: "next"}, Issues: []int{7}},
+			},
+		},
+		{
+			name: "no milestone",
+			want: []fakeMilestone{
+				{Milestone: github.Milestone{Number: 1, Title: "next"}},
+			},
+		},
+		{
+			name:       "prerelease",
+			prerelease: true,
+			milestones: []fakeMilestone{
+				{Milestone: github.Milestone{Number: 1, Title: "next"}, Issues: []int{7}},
+			},
+			want: []fakeMilestone{
+				{Milestone: github.Milestone{Number: 1, Title: "next"}, Issues: []int{7}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := gittest.New()
+			repo.Commit("feat: initial")
+			repo.Tag("v1.0.0")
+			repo.Commit("feat: login")
+
+			inputs := defaultInputs()
+			inputs.Milestones = true
+			inputs.CreateRelease = true
+			inputs.ReleasePrerelease = tt.prerelease
+			r, _ := newRunner(repo, inputs)
+			milestones := &fakeMilestoner{Milestones: tt.milestones}
+			r.Milestones = milestones
+
+			// A re-run resumes the finished release and must leave the new
+			// next milestone open.
+			for range 2 {
+				if _, err := r.Run(); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got, want := fmt.Sprintf("%+v", milestones.Milestones), fmt.Sprintf("%+v", tt.want); got != want {
+				t.Errorf("Milestones = %s, want %s", got, want)
+			}
+		})
+	}
+}
+
+func TestRunMilestonesBestEffort(t *testing.T) {
+	repo := gittest.New()
+	repo.Commit("feat: initial")
+	repo.Tag("v1.0.0")
+	repo.Commit("fix: crash")
+
+	inputs := defaultInputs()
+	inputs.Milestones = true
+	r, _ := newRunner(repo, inputs)
+	r.Milestones = &fakeMilestoner{Err: errors.New("HTTP 403")}
+	var log strings.Builder
+	r.Log = &log
+
+	result, err := r.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.NewVersion != "v1.0.1" || !strings.Contains(log.String(), "Warning: HTTP 403") {
+		t.Errorf("result = %+v, log = %q", result, log.String())
+	}
+}
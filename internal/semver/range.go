@@ -0,0 +1,52 @@
+package semver
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+var rangeRegex = regexp.MustCompile(`^(\d+)\.(?:(\d+)\.)?x$`)
+
+// Range is a maintenance version line such as "1.x" (any 1.y.z) or
+// "1.2.x" (any 1.2.z).
+type Range struct {
+	Major int
+	Minor int // -1 when any minor version is allowed
+}
+
+// ParseRange parses a range like "1.x" or "1.2.x".
+func ParseRange(s string) (Range, error) {
+	matches := rangeRegex.FindStringSubmatch(s)
+	if matches == nil {
+		return Range{}, fmt.Errorf("invalid version range: %q", s)
+	}
+
+	major, _ := strconv.Atoi(matches[1])
+	minor := -1
+	if matches[2] != "" {
+		minor, _ = strconv.Atoi(matches[2])
+	}
+	return Range{Major: major, Minor: minor}, nil
+}
+
+// Contains reports whether v falls within the range.
+func (r Range) Contains(v Version) bool {
+	if v.Major != r.Major {
+		return false
+	}
+	return r.Minor < 0 || v.Minor == r.Minor
+}
+
+// FixedMinor reports whether the range pins the minor version.
+func (r Range) FixedMinor() bool {
+	return r.Minor >= 0
+}
+
+// String returns the range as "1.x" or "1.2.x".
+func (r Range) String() string {
+	if r.FixedMinor() {
+		return fmt.Sprintf("%d.%d.x", r.Major, r.Minor)
+	}
+	return fmt.Sprintf("%d.x", r.Major)
+}
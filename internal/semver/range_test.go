@@ -0,0 +1,66 @@
+package semver
+
+import (
+	"testing"
+)
+
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Range
+		wantErr bool
+	}{
+		{"1.x", Range{Major: 1, Minor: -1}, false},
+		{"1.2.x", Range{Major: 1, Minor: 2}, false},
+		{"10.20.x", Range{Major: 10, Minor: 20}, false},
+		{"1.2.3", Range{}, true},
+		{"v1.x", Range{}, true},
+		{"x", Range{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseRange(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error for %q, got %v", tt.input, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error for %q: %v", tt.input, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseRange(%q) = %+v, want %+v", tt.input, got, tt.want)
+			}
+			if got.String() != tt.input {
+				t.Errorf("String() = %q, want %q", got.String(), tt.input)
+			}
+		})
+	}
+}
+
+func TestRangeContains(t *testing.T) {
+	major := Range{Major: 1, Minor: -1}
+	minor := Range{Major: 1, Minor: 2}
+
+	tests := []struct {
+		r    Range
+		v    string
+		want bool
+	}{
+		{major, "v1.0.0", true},
+		{major, "v1.9.3", true},
+		{major, "v2.0.0", false},
+		{minor, "v1.2.0", true},
+		{minor, "v1.2.9-rc.1", true},
+		{minor, "v1.3.0", false},
+		{minor, "v0.2.0", false},
+	}
+	for _, tt := range tests {
+		v, _ := Parse(tt.v)
+		if got := tt.r.Contains(v); got != tt.want {
+			t.Errorf("%s.Contains(%s) = %v, want %v", tt.r, tt.v, got, tt.want)
+		}
+	}
+}
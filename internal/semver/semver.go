@@ -4,9 +4,10 @@ import (
 	"fmt"
 	"regexp"
 	"strconv"
+	"strings"
 )
 
-var semverRegex = regexp.MustCompile(`^(v)?(\d+)\.(\d+)\.(\d+)(?:-([a-zA-Z0-9][a-zA-Z0-9.]*))??(?:\+([a-zA-Z0-9][a-zA-Z0-9.]*))?$`)
+var semverRegex = regexp.MustCompile(`^(v)?(\d+)\.(\d+)\.(\d+)(?:-([0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*))?(?:\+([0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*))?$`)
 
 // Version represents a parsed semantic version.
 type Version struct {
@@ -16,6 +17,7 @@ type Version struct {
 	Prerelease string
 	Metadata   string
 	Prefix     string
+	Suffix     string // appended after metadata; only set by TagFormat
 }
 
 // Parse parses a version string like "v1.2.3", "1.2.3-alpha.1", or "v1.0.0+build.123".
@@ -46,6 +48,7 @@ func (v Version) BumpMajor() Version {
 		Minor:  0,
 		Patch:  0,
 		Prefix: v.Prefix,
+		Suffix: v.Suffix,
 	}
 }
 
@@ -56,6 +59,7 @@ func (v Version) BumpMinor() Version {
 		Minor:  v.Minor + 1,
 		Patch:  0,
 		Prefix: v.Prefix,
+		Suffix: v.Suffix,
 	}
 }
 
@@ -66,10 +70,11 @@ func (v Version) BumpPatch() Version {
 		Minor:  v.Minor,
 		Patch:  v.Patch + 1,
 		Prefix: v.Prefix,
+		Suffix: v.Suffix,
 	}
 }
 
-// String returns the version as a string with its original prefix.
+// String returns the version as a string with its original prefix and suffix.
 func (v Version) String() string {
 	s := fmt.Sprintf("%s%d.%d.%d", v.Prefix, v.Major, v.Minor, v.Patch)
 	if v.Prerelease != "" {
@@ -78,7 +83,7 @@ func (v Version) String() string {
 	if v.Metadata != "" {
 		s += "+" + v.Metadata
 	}
-	return s
+	return s + v.Suffix
 }
 
 // Compare returns -1, 0, or 1 comparing v to other per semver precedence.
@@ -100,13 +105,37 @@ func (v Version) Compare(other Version) int {
 	if v.Prerelease != "" && other.Prerelease == "" {
 		return -1
 	}
-	if v.Prerelease < other.Prerelease {
-		return -1
+	return comparePrerelease(v.Prerelease, other.Prerelease)
+}
+
+// comparePrerelease compares dot-separated prerelease identifiers: numeric
+// identifiers compare numerically and sort before alphanumeric ones, and a
+// shorter list sorts first when all preceding identifiers are equal.
+func comparePrerelease(a, b string) int {
+	if a == b {
+		return 0
 	}
-	if v.Prerelease > other.Prerelease {
-		return 1
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		if as[i] == bs[i] {
+			continue
+		}
+		an, aErr := strconv.Atoi(as[i])
+		bn, bErr := strconv.Atoi(bs[i])
+		switch {
+		case aErr == nil && bErr == nil:
+			return cmpInt(an, bn)
+		case aErr == nil:
+			return -1
+		case bErr == nil:
+			return 1
+		case as[i] < bs[i]:
+			return -1
+		default:
+			return 1
+		}
 	}
-	return 0
+	return cmpInt(len(as), len(bs))
 }
 
 func cmpInt(a, b int) int {
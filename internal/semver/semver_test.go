@@ -17,12 +17,15 @@ func TestParse(t *testing.T) {
 		{"v1.0.0+build.123", Version{Major: 1, Prefix: "v", Metadata: "build.123"}, false},
 		{"v1.0.0-beta+build", Version{Major: 1, Prefix: "v", Prerelease: "beta", Metadata: "build"}, false},
 		{"v10.20.30", Version{Major: 10, Minor: 20, Patch: 30, Prefix: "v"}, false},
+		{"v1.3.0-feat-xyz.3f2a1bc", Version{Major: 1, Minor: 3, Prefix: "v", Prerelease: "feat-xyz.3f2a1bc"}, false},
 		// Invalid
 		{"v1.2", Version{}, true},
 		{"v1.2.3.4", Version{}, true},
 		{"abc", Version{}, true},
 		{"", Version{}, true},
 		{"v-1.2.3", Version{}, true},
+		{"v1.2.3-", Version{}, true},
+		{"v1.2.3-rc..1", Version{}, true},
 	}
 
 	for _, tt := range tests {
@@ -116,6 +119,11 @@ func TestCompare(t *testing.T) {
 		{"v1.0.0-alpha", "v1.0.0-beta", -1},
 		{"v1.0.0-beta", "v1.0.0-alpha", 1},
 		{"v1.0.0-alpha", "v1.0.0-alpha", 0},
+		// Numeric identifiers compare numerically
+		{"v1.0.0-next.10", "v1.0.0-next.9", 1},
+		{"v1.0.0-rc.1", "v1.0.0-rc.1.1", -1},
+		{"v1.0.0-1", "v1.0.0-alpha", -1},
+		{"v1.0.0-alpha.beta", "v1.0.0-alpha.1", 1},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,57 @@
+package semver
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TagFormat describes how versions are written as git tags, e.g. prefix
+// "release-" for "release-1.2.3" or suffix "-lts" for "v1.2.3-lts".
+type TagFormat struct {
+	Prefix string
+	Suffix string
+}
+
+// ParseTagPattern parses a tag pattern such as "release-*" or "v*-lts",
+// where the single "*" stands for the version.
+func ParseTagPattern(pattern string) (TagFormat, error) {
+	prefix, suffix, ok := strings.Cut(pattern, "*")
+	if !ok || strings.Contains(suffix, "*") {
+		return TagFormat{}, fmt.Errorf("invalid tag pattern %q: must contain exactly one *", pattern)
+	}
+	return TagFormat{Prefix: prefix, Suffix: suffix}, nil
+}
+
+// Pattern returns the format as a glob such as "release-*".
+func (f TagFormat) Pattern() string {
+	return f.Prefix + "*" + f.Suffix
+}
+
+// Parse strips the prefix and suffix from tag and parses the remaining
+// version. A "v" left after the prefix is kept as part of the prefix, so
+// "api/v1.2.3" parses with prefix "api/" into a version with prefix "api/v".
+func (f TagFormat) Parse(tag string) (Version, error) {
+	rest, ok := strings.CutPrefix(tag, f.Prefix)
+	if !ok {
+		return Version{}, fmt.Errorf("tag %q does not have prefix %q", tag, f.Prefix)
+	}
+	rest, ok = strings.CutSuffix(rest, f.Suffix)
+	if !ok {
+		return Version{}, fmt.Errorf("tag %q does not have suffix %q", tag, f.Suffix)
+	}
+
+	v, err := Parse(rest)
+	if err != nil {
+		return Version{}, err
+	}
+	v.Prefix = f.Prefix + v.Prefix
+	v.Suffix = f.Suffix
+	return v, nil
+}
+
+// Apply returns v with the format's prefix and suffix in place of its own.
+func (f TagFormat) Apply(v Version) Version {
+	v.Prefix = f.Prefix
+	v.Suffix = f.Suffix
+	return v
+}
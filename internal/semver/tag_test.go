@@ -0,0 +1,93 @@
+package semver
+
+import (
+	"testing"
+)
+
+func TestTagFormatParse(t *testing.T) {
+	tests := []struct {
+		format  TagFormat
+		tag     string
+		want    Version
+		wantErr bool
+	}{
+		{TagFormat{Prefix: "v"}, "v1.2.3", Version{Major: 1, Minor: 2, Patch: 3, Prefix: "v"}, false},
+		{TagFormat{Prefix: "release-"}, "release-1.2.3", Version{Major: 1, Minor: 2, Patch: 3, Prefix: "release-"}, false},
+		{TagFormat{Prefix: "api/v"}, "api/v2.0.0-rc.1", Version{Major: 2, Prefix: "api/v", Prerelease: "rc.1"}, false},
+		{TagFormat{Prefix: "api/"}, "api/v2.0.0", Version{Major: 2, Prefix: "api/v"}, false},
+		{TagFormat{}, "v1.0.0", Version{Major: 1, Prefix: "v"}, false},
+		{TagFormat{Prefix: "v", Suffix: "-lts"}, "v1.2.3-lts", Version{Major: 1, Minor: 2, Patch: 3, Prefix: "v", Suffix: "-lts"}, false},
+		{TagFormat{Prefix: "v", Suffix: "-lts"}, "v1.2.3-rc.1-lts", Version{Major: 1, Minor: 2, Patch: 3, Prefix: "v", Prerelease: "rc.1", Suffix: "-lts"}, false},
+		// Invalid
+		{TagFormat{Prefix: "release-"}, "v1.2.3", Version{}, true},
+		{TagFormat{Prefix: "v", Suffix: "-lts"}, "v1.2.3", Version{}, true},
+		{TagFormat{Prefix: "release-"}, "release-1.2", Version{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			got, err := tt.format.Parse(tt.tag)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error for %q, got %v", tt.tag, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error for %q: %v", tt.tag, err)
+			}
+			if got != tt.want {
+				t.Errorf("Parse(%q) = %+v, want %+v", tt.tag, got, tt.want)
+			}
+			if got.String() != tt.tag {
+				t.Errorf("String() = %q, want %q", got.String(), tt.tag)
+			}
+		})
+	}
+}
+
+func TestParseTagPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    TagFormat
+		wantErr bool
+	}{
+		{"v*", TagFormat{Prefix: "v"}, false},
+		{"release-*", TagFormat{Prefix: "release-"}, false},
+		{"*-lts", TagFormat{Suffix: "-lts"}, false},
+		{"*", TagFormat{}, false},
+		{"release-", TagFormat{}, true},
+		{"*.*", TagFormat{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseTagPattern(tt.pattern)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseTagPattern(%q) error = %v, wantErr %v", tt.pattern, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseTagPattern(%q) = %+v, want %+v", tt.pattern, got, tt.want)
+		}
+		if !tt.wantErr && got.Pattern() != tt.pattern {
+			t.Errorf("Pattern() = %q, want %q", got.Pattern(), tt.pattern)
+		}
+	}
+}
+
+func TestTagFormatApply(t *testing.T) {
+	v := Version{Major: 1, Prefix: "v"}
+
+	if got := (TagFormat{Prefix: "release-", Suffix: "-lts"}).Apply(v).String(); got != "release-1.0.0-lts" {
+		t.Errorf("Apply() = %s, want release-1.0.0-lts", got)
+	}
+	if got := (TagFormat{}).Apply(v).String(); got != "1.0.0" {
+		t.Errorf("Apply() = %s, want 1.0.0", got)
+	}
+}
+
+func TestBumpPreservesSuffix(t *testing.T) {
+	v, _ := TagFormat{Prefix: "release-", Suffix: "-lts"}.Parse("release-1.2.3-lts")
+	if got := v.BumpMinor().String(); got != "release-1.3.0-lts" {
+		t.Errorf("BumpMinor() = %s, want release-1.3.0-lts", got)
+	}
+}
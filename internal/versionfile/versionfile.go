@@ -0,0 +1,265 @@
+// Package versionfile locates and rewrites the version field in project
+// files such as package.json, Cargo.toml or a Go version constant. Files are
+// edited in place as text, so formatting and comments are preserved.
+package versionfile
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Kind identifies how the version is stored in a file.
+type Kind string
+
+const (
+	PackageJSON Kind = "package.json"
+	Cargo       Kind = "Cargo.toml"
+	Pyproject   Kind = "pyproject.toml"
+	Chart       Kind = "Chart.yaml"
+	Pom         Kind = "pom.xml"
+	Go          Kind = "go"
+	Text        Kind = "text"
+	Regex       Kind = "regex"
+)
+
+// File is a project file that carries a version.
+type File struct {
+	Path string
+	Kind Kind
+	// Pattern locates the version for Regex files: the "version" named
+	// group if it has one, otherwise the first group or the whole match.
+	Pattern *regexp.Regexp
+}
+
+// ParseFiles parses a list of files, one per line or separated by commas.
+// Each entry is a path whose kind is detected from its name, or
+// "path: regex" for any other file. Blank lines and lines starting with '#'
+// are ignored. Regex entries must be on their own line.
+func ParseFiles(s string) ([]File, error) {
+	var files []File
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if path, expr, ok := strings.Cut(line, ": "); ok {
+			re, err := regexp.Compile(strings.TrimSpace(expr))
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern for %s: %w", path, err)
+			}
+			files = append(files, File{Path: strings.TrimSpace(path), Kind: Regex, Pattern: re})
+			continue
+		}
+		for _, path := range strings.Split(line, ",") {
+			if path = strings.TrimSpace(path); path == "" {
+				continue
+			}
+			kind, ok := detect(path)
+			if !ok {
+				return nil, fmt.Errorf("unsupported version file %s: use \"%s: <regex>\" to locate its version", path, path)
+			}
+			files = append(files, File{Path: path, Kind: kind})
+		}
+	}
+	return files, nil
+}
+
+func detect(path string) (Kind, bool) {
+	switch base := filepath.Base(path); {
+	case base == "package.json":
+		return PackageJSON, true
+	case base == "Cargo.toml":
+		return Cargo, true
+	case base == "pyproject.toml":
+		return Pyproject, true
+	case base == "Chart.yaml" || base == "Chart.yml":
+		return Chart, true
+	case base == "pom.xml":
+		return Pom, true
+	case strings.HasSuffix(base, ".go"):
+		return Go, true
+	case base == "VERSION" || base == "VERSION.txt":
+		return Text, true
+	}
+	return "", false
+}
+
+// Read returns the version in content, the first if f has several fields.
+func (f File) Read(content []byte) (string, error) {
+	spans, err := f.locate(content)
+	if err != nil {
+		return "", err
+	}
+	return string(content[spans[0].start:spans[0].end]), nil
+}
+
+// Update returns content with every version field of f replaced by version.
+func (f File) Update(content []byte, version string) ([]byte, error) {
+	spans, err := f.locate(content)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, 0, len(content)+len(version)*len(spans))
+	last := 0
+	for _, s := range spans {
+		out = append(out, content[last:s.start]...)
+		out = append(out, version...)
+		last = s.end
+	}
+	return append(out, content[last:]...), nil
+}
+
+// span is the byte range of a version value within a file.
+type span struct{ start, end int }
+
+// locate returns the spans of f's version fields in order of appearance.
+func (f File) locate(content []byte) ([]span, error) {
+	var spans []span
+	switch f.Kind {
+	case PackageJSON:
+		spans = locateJSON(content)
+	case Cargo:
+		spans = locateTOML(content, "package", "workspace.package")
+	case Pyproject:
+		spans = locateTOML(content, "project", "tool.poetry")
+	case Chart:
+		spans = submatches(chartRegex, content, 1)
+	case Pom:
+		spans = locatePom(content)
+	case Go:
+		spans = submatches(goRegex, content, 1)
+	case Text:
+		if trimmed := strings.TrimSpace(string(content)); trimmed != "" {
+			start := strings.Index(string(content), trimmed)
+			spans = []span{{start, start + len(trimmed)}}
+		}
+	case Regex:
+		group := 0
+		if i := f.Pattern.SubexpIndex("version"); i > 0 {
+			group = i
+		} else if f.Pattern.NumSubexp() > 0 {
+			group = 1
+		}
+		spans = submatches(f.Pattern, content, group)
+	default:
+		return nil, fmt.Errorf("%s: unknown version file kind %q", f.Path, f.Kind)
+	}
+	if len(spans) == 0 {
+		return nil, fmt.Errorf("%s: no version field found", f.Path)
+	}
+	return spans, nil
+}
+
+var (
+	jsonVersionRegex = regexp.MustCompile(`"version"\s*:\s*"([^"\\]*)"`)
+	tomlSectionRegex = regexp.MustCompile(`^\s*\[([^\[\]]+)\]`)
+	tomlVersionRegex = regexp.MustCompile(`^\s*version\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+	chartRegex       = regexp.MustCompile(`(?m)^(?:version|appVersion):[ \t]*["']?([^"'\s#]+)`)
+	goRegex          = regexp.MustCompile(`(?m)^\s*(?:const\s+|var\s+)?Version\s*(?:string\s*)?=\s*"([^"]*)"`)
+	xmlTokenRegex    = regexp.MustCompile(`(?s)<!--.*?-->|<!\[CDATA\[.*?\]\]>|<[?!].*?>|<(/?)([\w:.-]+)[^>]*?(/?)>`)
+)
+
+// submatches returns the spans of group in every match of re.
+func submatches(re *regexp.Regexp, content []byte, group int) []span {
+	var spans []span
+	for _, m := range re.FindAllSubmatchIndex(content, -1) {
+		if m[2*group] >= 0 {
+			spans = append(spans, span{m[2*group], m[2*group+1]})
+		}
+	}
+	return spans
+}
+
+// locateJSON returns the span of the top-level "version" string.
+func locateJSON(content []byte) []span {
+	for _, m := range jsonVersionRegex.FindAllSubmatchIndex(content, -1) {
+		if jsonDepth(content[:m[0]]) == 1 {
+			return []span{{m[2], m[3]}}
+		}
+	}
+	return nil
+}
+
+// jsonDepth returns the object/array nesting depth at the end of prefix.
+func jsonDepth(prefix []byte) int {
+	depth := 0
+	inString, escaped := false, false
+	for _, c := range prefix {
+		switch {
+		case escaped:
+			escaped = false
+		case inString && c == '\\':
+			escaped = true
+		case c == '"':
+			inString = !inString
+		case inString:
+		case c == '{' || c == '[':
+			depth++
+		case c == '}' || c == ']':
+			depth--
+		}
+	}
+	if inString {
+		return -1
+	}
+	return depth
+}
+
+// locateTOML returns the span of the version key in the first of sections
+// that has one.
+func locateTOML(content []byte, sections ...string) []span {
+	found := map[string]span{}
+	section := ""
+	offset := 0
+	for _, line := range strings.SplitAfter(string(content), "\n") {
+		if m := tomlSectionRegex.FindStringSubmatch(line); m != nil {
+			section = strings.TrimSpace(m[1])
+		} else if m := tomlVersionRegex.FindStringSubmatchIndex(line); m != nil {
+			if _, ok := found[section]; !ok {
+				g := 1
+				if m[2] < 0 {
+					g = 2
+				}
+				found[section] = span{offset + m[2*g], offset + m[2*g+1]}
+			}
+		}
+		offset += len(line)
+	}
+	for _, s := range sections {
+		if sp, ok := found[s]; ok {
+			return []span{sp}
+		}
+	}
+	return nil
+}
+
+// locatePom returns the span of the project's own <version>, ignoring the
+// versions of the parent, dependencies and plugins.
+func locatePom(content []byte) []span {
+	var stack []string
+	for _, m := range xmlTokenRegex.FindAllSubmatchIndex(content, -1) {
+		if m[4] < 0 {
+			continue // comment, CDATA, declaration or processing instruction
+		}
+		name := string(content[m[4]:m[5]])
+		switch {
+		case m[3] > m[2]: // closing tag
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		case m[7] > m[6]: // self-closing tag
+		default:
+			if name == "version" && len(stack) == 1 && stack[0] == "project" {
+				end := strings.Index(string(content[m[1]:]), "</version>")
+				if end < 0 {
+					return nil
+				}
+				return []span{{m[1], m[1] + end}}
+			}
+			stack = append(stack, name)
+		}
+	}
+	return nil
+}
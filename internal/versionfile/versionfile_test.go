@@ -0,0 +1,194 @@
+package versionfile
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestParseFiles(t *testing.T) {
+	files, err := ParseFiles("package.json, charts/app/Chart.yaml\n# comment\n\ninternal/version/version.go\nVERSION\nversion.txt: ^(.*)$")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		path string
+		kind Kind
+	}{
+		{"package.json", PackageJSON},
+		{"charts/app/Chart.yaml", Chart},
+		{"internal/version/version.go", Go},
+		{"VERSION", Text},
+		{"version.txt", Regex},
+	}
+	if len(files) != len(want) {
+		t.Fatalf("got %d files, want %d", len(files), len(want))
+	}
+	for i, w := range want {
+		if files[i].Path != w.path || files[i].Kind != w.kind {
+			t.Errorf("files[%d] = %s (%s), want %s (%s)", i, files[i].Path, files[i].Kind, w.path, w.kind)
+		}
+	}
+	if files[4].Pattern == nil {
+		t.Error("regex file has no pattern")
+	}
+
+	for _, s := range []string{"setup.cfg", "VERSION: ("} {
+		if _, err := ParseFiles(s); err == nil {
+			t.Errorf("ParseFiles(%q) expected error", s)
+		}
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    File
+		content string
+		want    string
+	}{
+		{
+			"package.json",
+			File{Kind: PackageJSON},
+			`{
+  "name": "app",
+  "config": {"version": "keep"},
+  "version": "1.2.3",
+  "dependencies": {"lib": "^1.0.0"}
+}`,
+			`{
+  "name": "app",
+  "config": {"version": "keep"},
+  "version": "1.3.0",
+  "dependencies": {"lib": "^1.0.0"}
+}`,
+		},
+		{
+			"Cargo.toml",
+			File{Kind: Cargo},
+			"[package]\nname = \"app\"\nversion = \"1.2.3\" # bumped on release\n\n[dependencies]\nserde = { version = \"1.0\" }\n",
+			"[package]\nname = \"app\"\nversion = \"1.3.0\" # bumped on release\n\n[dependencies]\nserde = { version = \"1.0\" }\n",
+		},
+		{
+			"Cargo.toml workspace",
+			File{Kind: Cargo},
+			"[workspace]\nmembers = [\"a\"]\n\n[workspace.package]\nversion = '1.2.3'\n",
+			"[workspace]\nmembers = [\"a\"]\n\n[workspace.package]\nversion = '1.3.0'\n",
+		},
+		{
+			"pyproject.toml",
+			File{Kind: Pyproject},
+			"[build-system]\nrequires = [\"hatchling\"]\n\n[project]\nname = \"app\"\nversion = \"1.2.3\"\n",
+			"[build-system]\nrequires = [\"hatchling\"]\n\n[project]\nname = \"app\"\nversion = \"1.3.0\"\n",
+		},
+		{
+			"pyproject.toml poetry",
+			File{Kind: Pyproject},
+			"[tool.poetry]\nname = \"app\"\nversion = \"1.2.3\"\n",
+			"[tool.poetry]\nname = \"app\"\nversion = \"1.3.0\"\n",
+		},
+		{
+			"Chart.yaml",
+			File{Kind: Chart},
+			"apiVersion: v2\nname: app\nversion: 1.2.3\nappVersion: \"1.2.3\"\ndependencies:\n  - name: redis\n    version: 17.0.0\n",
+			"apiVersion: v2\nname: app\nversion: 1.3.0\nappVersion: \"1.3.0\"\ndependencies:\n  - name: redis\n    version: 17.0.0\n",
+		},
+		{
+			"pom.xml",
+			File{Kind: Pom},
+			`<?xml version="1.0"?>
+<project>
+  <parent><groupId>org</groupId><version>9.0</version></parent>
+  <!-- <version>0.0.1</version> -->
+  <artifactId>app</artifactId>
+  <version>1.2.3</version>
+  <dependencies><dependency><version>2.0</version></dependency></dependencies>
+</project>`,
+			`<?xml version="1.0"?>
+<project>
+  <parent><groupId>org</groupId><version>9.0</version></parent>
+  <!-- <version>0.0.1</version> -->
+  <artifactId>app</artifactId>
+  <version>1.3.0</version>
+  <dependencies><dependency><version>2.0</version></dependency></dependencies>
+</project>`,
+		},
+		{
+			"version.go",
+			File{Kind: Go},
+			"package version\n\n// Version is the release version.\nconst Version = \"1.2.3\"\n",
+			"package version\n\n// Version is the release version.\nconst Version = \"1.3.0\"\n",
+		},
+		{
+			"VERSION",
+			File{Kind: Text},
+			"1.2.3\n",
+			"1.3.0\n",
+		},
+		{
+			"regex with named group",
+			File{Kind: Regex, Pattern: regexp.MustCompile(`image: app:(?P<version>[\w.-]+)`)},
+			"image: app:1.2.3\nimage: db:1.2.3\n",
+			"image: app:1.3.0\nimage: db:1.2.3\n",
+		},
+		{
+			"regex without group",
+			File{Kind: Regex, Pattern: regexp.MustCompile(`(?m)^\d+\.\d+\.\d+$`)},
+			"1.2.3\n",
+			"1.3.0\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.file.Update([]byte(tt.content), "1.3.0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Update() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdateNoVersion(t *testing.T) {
+	files := []File{
+		{Path: "package.json", Kind: PackageJSON},
+		{Path: "Cargo.toml", Kind: Cargo},
+		{Path: "pom.xml", Kind: Pom},
+	}
+	content := []byte(`{"name": "x", "deps": {"version": "1"}} [dependencies] version = "1" <project></project>`)
+	for _, f := range files {
+		if _, err := f.Update(content, "1.0.0"); err == nil {
+			t.Errorf("%s: expected error", f.Path)
+		}
+	}
+}
+
+func TestRead(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    File
+		content string
+		want    string
+	}{
+		{"VERSION", File{Kind: Text}, "  v1.2.3\n", "v1.2.3"},
+		{"package.json", File{Kind: PackageJSON}, `{"dependencies": {"version": "0.1.0"}, "version": "1.2.3"}`, "1.2.3"},
+		{"Chart.yaml", File{Kind: Chart}, "version: 1.2.3\nappVersion: 4.5.6\n", "1.2.3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.file.Read([]byte(tt.content))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Read() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := (File{Path: "VERSION", Kind: Text}).Read([]byte("\n")); err == nil {
+		t.Error("expected error for empty VERSION")
+	}
+}